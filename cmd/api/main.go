@@ -1,69 +1,266 @@
 package main
 
 import (
-	"log"
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
 
-	"payment-gateway-manjo/backend/internal/delivery/http/handler"
-	"payment-gateway-manjo/backend/internal/delivery/http/middleware"
+	"payment-gateway-manjo/backend/internal/delivery/http/router"
+	"payment-gateway-manjo/backend/internal/delivery/worker"
+	"payment-gateway-manjo/backend/internal/domain/pubsub"
+	"payment-gateway-manjo/backend/internal/domain/repository"
+	"payment-gateway-manjo/backend/internal/infrastructure/broker"
 	"payment-gateway-manjo/backend/internal/infrastructure/config"
 	"payment-gateway-manjo/backend/internal/infrastructure/database"
+	"payment-gateway-manjo/backend/internal/infrastructure/health"
+	"payment-gateway-manjo/backend/internal/infrastructure/logging"
+	"payment-gateway-manjo/backend/internal/infrastructure/metrics"
+	"payment-gateway-manjo/backend/internal/infrastructure/outbox"
+	"payment-gateway-manjo/backend/internal/infrastructure/tracing"
 	"payment-gateway-manjo/backend/internal/usecase"
-
-	"github.com/gin-contrib/cors"
-	"github.com/gin-gonic/gin"
 )
 
 func main() {
-	cfg, err := config.LoadConfig()
+	cfg, args, err := config.LoadConfig(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
 	if err != nil {
-		log.Fatal("Failed to load config:", err)
+		// Logging is not configured yet and the error spans several lines.
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
 	}
 
+	logger, err := logging.New(os.Stdout, cfg.Log.Level)
+	if err != nil {
+		fatal("failed to configure logging", err)
+	}
+	slog.SetDefault(logger)
+
 	db, err := database.NewPostgresDB(cfg)
 	if err != nil {
-		log.Fatal("Failed to connect to database:", err)
+		fatal("failed to connect to database", err)
+	}
+
+	replicaDB, err := database.NewReadReplica(cfg, db)
+	if err != nil {
+		fatal("failed to connect to read replica", err)
+	}
+
+	migrator, err := database.NewMigrator(db)
+	if err != nil {
+		fatal("failed to load migrations", err)
+	}
+
+	if len(args) > 0 && args[0] == "migrate" {
+		if err := runMigrate(migrator, args[1:]); err != nil {
+			fatal("migration failed", err)
+		}
+		return
 	}
 
-	transactionRepo := database.NewTransactionRepository(db)
+	if err := migrator.CheckUpToDate(context.Background()); err != nil {
+		fatal("refusing to start", err)
+	}
 
-	qrUsecase := usecase.NewQRGeneratorUsecase(transactionRepo)
-	paymentUsecase := usecase.NewPaymentUsecase(transactionRepo)
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing)
+	if err != nil {
+		fatal("failed to set up tracing", err)
+	}
 
-	qrHandler := handler.NewQRHandler(qrUsecase)
-	paymentHandler := handler.NewPaymentHandler(paymentUsecase)
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
 
-	signatureValidator := middleware.NewSignatureValidator(cfg.Security.SecretKey)
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	var workers sync.WaitGroup
+	runWorker := func(run func(ctx context.Context)) {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			run(workerCtx)
+		}()
+	}
 
-	router := gin.Default()
+	sqlDB, err := db.DB()
+	if err != nil {
+		fatal("failed to get database handle", err)
+	}
 
-	router.Use(cors.New(cors.Config{
-		AllowOrigins:     []string{"http://localhost:3000", "http://localhost:5173"},
-		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "X-Signature"},
-		ExposeHeaders:    []string{"Content-Length"},
-		AllowCredentials: true,
-	}))
+	if cfg.Features.Metrics {
+		if err := metrics.RegisterDB(sqlDB, cfg.Database.DBName); err != nil {
+			fatal("failed to register database metrics", err)
+		}
+	}
 
-	router.GET("/health", func(c *gin.Context) {
-		c.JSON(200, gin.H{"status": "OK"})
-	})
+	probe := health.NewProbe(cfg.Server.HealthTimeout)
+	probe.Register("database", sqlDB.PingContext)
+	probe.Register("migrations", migrator.CheckUpToDate)
+
+	var replicaSQLDB *sql.DB
+	if replicaDB != db {
+		if replicaSQLDB, err = replicaDB.DB(); err != nil {
+			fatal("failed to get read replica handle", err)
+		}
+		probe.Register("read_replica", replicaSQLDB.PingContext)
+		if cfg.Features.Metrics {
+			if err := metrics.RegisterDB(replicaSQLDB, cfg.Database.DBName+"_replica"); err != nil {
+				fatal("failed to register read replica metrics", err)
+			}
+		}
+	}
+
+	transactionRepo := database.NewTransactionRepository(db, replicaDB)
+	transactionEventRepo := database.NewTransactionEventRepository(db)
+	// Status changes reach real-time clients, and wake the outbox relay,
+	// through the broker. With postgres they are sent with pg_notify in the
+	// transaction that makes them, so every replica hears about them.
+	var statusChanges broker.Broker
+	var transactor repository.Transactor
+	switch cfg.Realtime.Broker {
+	case config.BrokerPostgres:
+		postgresBroker := broker.NewPostgresBroker(db, database.PrimaryDSN(cfg), cfg.Realtime.Channel, cfg.Realtime.BufferSize)
+		probe.Register("status_listener", postgresBroker.Check)
+		runWorker(postgresBroker.Run)
+		statusChanges = postgresBroker
+		transactor = database.NewNotifyingTransactor(db, cfg.Realtime.Channel)
+	default:
+		statusChanges = broker.NewMemoryBroker(cfg.Realtime.BufferSize)
+		transactor = database.NewTransactor(db, statusChanges)
+	}
 
-	v1 := router.Group("/api/v1")
-	{
-		qr := v1.Group("/qr")
-		{
-			qr.POST("/generate", signatureValidator.ValidateQRSignature(), qrHandler.GenerateQR)
-			qr.POST("/payment", signatureValidator.ValidatePaymentSignature(), paymentHandler.ProcessPayment)
+	eventBus := outbox.NewBus()
+	if cfg.Features.OutboxRelay {
+		sinks, err := outbox.NewSinks(cfg.Outbox, eventBus)
+		if err != nil {
+			fatal("failed to configure outbox sinks", err)
 		}
+		// The relay beats between events, so the longest silence is one
+		// webhook call on top of the usual poll.
+		relayHeartbeat := health.NewHeartbeat(heartbeatMaxAge(cfg.Outbox.PollInterval) + cfg.Outbox.WebhookTimeout)
+		probe.Register("outbox_relay", relayHeartbeat.Check)
+		relay := outbox.NewRelay(transactor, sinks, outbox.RelayConfig{
+			PollInterval: cfg.Outbox.PollInterval,
+			BatchSize:    cfg.Outbox.BatchSize,
+			MaxAttempts:  cfg.Outbox.MaxAttempts,
+			Lease:        cfg.Outbox.Lease,
+		}, statusChanges, relayHeartbeat)
+		runWorker(relay.Run)
+	}
+
+	timeouts := usecase.Timeouts{
+		GenerateQR:     cfg.Timeouts.GenerateQR,
+		ProcessPayment: cfg.Timeouts.ProcessPayment,
+		Query:          cfg.Timeouts.Query,
+		ExpireBatch:    cfg.Timeouts.ExpireBatch,
+	}
+	qrUsecase := usecase.NewTracedQRGeneratorUsecase(
+		usecase.NewQRGeneratorUsecase(transactor, timeouts),
+	)
+	paymentUsecase := usecase.NewTracedPaymentUsecase(
+		usecase.NewPaymentUsecase(transactionRepo, transactionEventRepo, transactor, timeouts, cfg.Payment.PaidTimeMaxSkew),
+	)
+
+	if cfg.Features.ExpiryWorker {
+		expiryHeartbeat := health.NewHeartbeat(heartbeatMaxAge(cfg.Expiry.Interval) + cfg.Timeouts.ExpireBatch)
+		probe.Register("expiry_worker", expiryHeartbeat.Check)
+		expiryWorker := worker.NewExpiryWorker(paymentUsecase, cfg.Expiry.QRTTL, cfg.Expiry.Interval, cfg.Expiry.BatchSize, expiryHeartbeat)
+		runWorker(expiryWorker.Run)
+	}
+
+	engine, err := router.New(cfg, router.Dependencies{
+		QRUsecase:      qrUsecase,
+		PaymentUsecase: paymentUsecase,
+		StatusChanges:  statusChanges,
+		Probe:          probe,
+		Logger:         logger,
+	})
+	if err != nil {
+		fatal("failed to build router", err)
+	}
+	if cfg.Security.LegacySignatures {
+		slog.Warn("legacy field signatures are accepted; they leave part of each request unsigned and are removed on 2027-03-31")
+	}
 
-		transactions := v1.Group("/transactions")
-		{
-			transactions.GET("", paymentHandler.GetTransactions)
+	server := &http.Server{
+		Addr:              ":" + cfg.Server.Port,
+		Handler:           engine,
+		ReadTimeout:       cfg.Server.ReadTimeout,
+		ReadHeaderTimeout: cfg.Server.ReadHeaderTimeout,
+		WriteTimeout:      cfg.Server.WriteTimeout,
+		IdleTimeout:       cfg.Server.IdleTimeout,
+		MaxHeaderBytes:    cfg.Server.MaxHeaderBytes,
+	}
+	// Shutdown waits for open requests, which status streams would keep
+	// open; ending them sends their clients to another replica.
+	server.RegisterOnShutdown(func() { statusChanges.Interrupt(pubsub.ErrInterrupted) })
+
+	serverErr := make(chan error, 1)
+	go func() {
+		slog.Info("server starting", "port", cfg.Server.Port)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serverErr <- err
 		}
+	}()
+
+	select {
+	case err := <-serverErr:
+		slog.Error("server failed", "error", err)
+	case <-ctx.Done():
+		slog.Info("shutdown signal received, failing readiness before draining")
+		probe.MarkShuttingDown()
+		time.Sleep(cfg.Server.ShutdownDelay)
 	}
+	stop()
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	defer cancel()
 
-	log.Printf("Server starting on port %s", cfg.Server.Port)
-	if err := router.Run(":" + cfg.Server.Port); err != nil {
-		log.Fatal("Failed to start server:", err)
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		slog.Error("http server shutdown failed", "error", err)
 	}
-}
\ No newline at end of file
+
+	stopWorkers()
+	workersDone := make(chan struct{})
+	go func() {
+		workers.Wait()
+		close(workersDone)
+	}()
+	select {
+	case <-workersDone:
+	case <-shutdownCtx.Done():
+		slog.Warn("background workers did not stop before the shutdown timeout")
+	}
+
+	if err := sqlDB.Close(); err != nil {
+		slog.Error("database close failed", "error", err)
+	}
+	if replicaSQLDB != nil {
+		if err := replicaSQLDB.Close(); err != nil {
+			slog.Error("read replica close failed", "error", err)
+		}
+	}
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		slog.Error("tracing shutdown failed", "error", err)
+	}
+	slog.Info("server stopped")
+}
+
+// heartbeatMaxAge tolerates a few missed ticks before a worker is reported
+// as stuck.
+func heartbeatMaxAge(interval time.Duration) time.Duration {
+	return 3*interval + 10*time.Second
+}
+
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+
+	"payment-gateway-manjo/backend/pkg/crypto"
+)
+
+const keysUsage = `usage: gatewayctl keys <rotate|feed> [arguments]
+  keys rotate                                 generate the next SECRET_KEY and print the rotation steps
+  keys feed [-feed-secret key] <merchantId>   print the key a merchant signs its feed with`
+
+// rotationSteps walks an operator through replacing SECRET_KEY without
+// rejecting requests partners sign with either key in the meantime.
+const rotationSteps = `Rotation steps:
+  1. Set SECRET_KEY_NEXT to the new key on every API replica and restart
+     them. Requests signed with either key are accepted from then on.
+  2. Hand the new key to partners and have them sign with it.
+  3. Watch payment_gateway_next_key_signatures_total until it grows as fast
+     as signed traffic, i.e. no partner signs with the old key any more.
+  4. If OUTBOX_WEBHOOK_SECRET is unset, webhooks are signed with SECRET_KEY:
+     give webhook receivers the new key before the next step.
+  5. Set SECRET_KEY to the new key, remove SECRET_KEY_NEXT and restart the
+     replicas.
+`
+
+func runKeys(args []string, feedSecret string) error {
+	if len(args) == 0 {
+		return errors.New(keysUsage)
+	}
+	switch args[0] {
+	case "rotate":
+		return keysRotate(args[1:])
+	case "feed":
+		return keysFeed(args[1:], feedSecret)
+	default:
+		return errors.New(keysUsage)
+	}
+}
+
+// keysRotate generates a 256-bit key. It changes nothing itself: the key only
+// takes effect once it is configured as SECRET_KEY_NEXT.
+func keysRotate(args []string) error {
+	if len(args) != 0 {
+		return errors.New("usage: gatewayctl keys rotate")
+	}
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return fmt.Errorf("keys rotate: failed to generate a key: %w", err)
+	}
+	encoded := hex.EncodeToString(key)
+	fmt.Printf("New key: %s\n\n%s", encoded, rotationSteps)
+	return nil
+}
+
+// keysFeed prints the key a merchant signs its feed requests with. It is
+// derived from the feed secret, so it can be printed again at any time and
+// changes for every merchant when the feed secret does.
+func keysFeed(args []string, feedSecret string) error {
+	fs := flag.NewFlagSet("keys feed", flag.ContinueOnError)
+	secret := fs.String("feed-secret", feedSecret, "feed secret (default $SECURITY_FEED_SECRET from the environment or .env)")
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return errors.New(keysUsage)
+	}
+	if *secret == "" {
+		return errors.New("keys feed: a feed secret is required: set -feed-secret or SECURITY_FEED_SECRET")
+	}
+
+	fmt.Println(crypto.DeriveMerchantKey(positional[0], *secret))
+	return nil
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"payment-gateway-manjo/backend/pkg/crypto"
+)
+
+func TestKeysRotate(t *testing.T) {
+	keyLine := regexp.MustCompile(`(?m)^New key: ([0-9a-f]{64})$`)
+	keys := make(map[string]bool)
+	for i := 0; i < 2; i++ {
+		output, err := captureStdout(t, func() error { return runKeys([]string{"rotate"}, "") })
+		if err != nil {
+			t.Fatalf("keys rotate error = %v", err)
+		}
+		match := keyLine.FindStringSubmatch(output)
+		if match == nil {
+			t.Fatalf("output has no 256-bit hex key:\n%s", output)
+		}
+		if !strings.Contains(output, "SECRET_KEY_NEXT") {
+			t.Errorf("output does not explain the rotation:\n%s", output)
+		}
+		keys[match[1]] = true
+	}
+	if len(keys) != 2 {
+		t.Error("keys rotate printed the same key twice")
+	}
+}
+
+func TestKeysFeed(t *testing.T) {
+	output, err := captureStdout(t, func() error { return runKeys([]string{"feed", "MERCHANT-1"}, "feed-secret") })
+	if err != nil {
+		t.Fatalf("keys feed error = %v", err)
+	}
+	if got, want := strings.TrimSpace(output), crypto.DeriveMerchantKey("MERCHANT-1", "feed-secret"); got != want {
+		t.Errorf("key = %s, want %s", got, want)
+	}
+	if err := runKeys([]string{"feed", "MERCHANT-1"}, ""); err == nil {
+		t.Error("keys feed worked without a feed secret")
+	}
+}
@@ -0,0 +1,92 @@
+// Command gatewayctl is the operator tool for the payment gateway: it signs
+// request bodies, issues keys, decodes QR content and inspects or administers
+// transactions straight in the database.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"payment-gateway-manjo/backend/internal/infrastructure/config"
+
+	"github.com/joho/godotenv"
+)
+
+const usage = `usage: gatewayctl [config flags] <command> [arguments]
+
+Commands that need no database:
+  sign [-secret key] [-legacy qr|payment] [file]   print X-Signature for a body
+  sign [-feed-secret key] -feed merchantId         print the query that opens a merchant's live feed
+  keys rotate                                      generate the next SECRET_KEY and print the rotation steps
+  keys feed [-feed-secret key] <merchantId>        print the key a merchant signs its feed with
+  decode-qr [content]                              print what a QR encodes
+
+sign and keys read SECRET_KEY and SECURITY_FEED_SECRET from the environment
+or .env unless given as flags; they do not load --config files.
+
+Commands that use the database configured like the API server
+(environment, .env, --config file or the same flags):
+  tx get <referenceNo>
+  tx list [-merchant id] [-status s] [-partner-ref r] [-json]
+  tx history <referenceNo> [-json]
+  tx expire [-older-than d] [-limit n]
+  tx mark-failed <referenceNo> -reason text [-actor name]
+
+Input that is not given as an argument is read from stdin.`
+
+func main() {
+	slog.SetDefault(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelWarn})))
+
+	if err := run(os.Args[1:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return
+		}
+		fmt.Fprintln(os.Stderr, "gatewayctl:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string) error {
+	if len(args) == 0 {
+		return errors.New(usage)
+	}
+
+	// Commands named first skip loading the configuration, so signing or
+	// decoding does not require database credentials.
+	var cfg *config.Config
+	switch args[0] {
+	case "sign", "keys", "decode-qr", "help", "-h", "--help":
+	default:
+		loaded, rest, err := config.LoadConfig(args)
+		if err != nil {
+			return err
+		}
+		cfg, args = loaded, rest
+		if len(args) == 0 {
+			return errors.New(usage)
+		}
+	}
+
+	switch args[0] {
+	case "sign", "keys":
+		if err := godotenv.Load(); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to load .env file: %w", err)
+		}
+		if args[0] == "keys" {
+			return runKeys(args[1:], os.Getenv("SECURITY_FEED_SECRET"))
+		}
+		return runSign(args[1:], os.Getenv("SECRET_KEY"), os.Getenv("SECURITY_FEED_SECRET"))
+	case "decode-qr":
+		return runDecodeQR(args[1:])
+	case "tx":
+		return runTx(cfg, args[1:])
+	case "help", "-h", "--help":
+		fmt.Println(usage)
+		return nil
+	default:
+		return fmt.Errorf("unknown command %q\n%s", args[0], usage)
+	}
+}
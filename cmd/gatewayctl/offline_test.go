@@ -0,0 +1,88 @@
+package main
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"payment-gateway-manjo/backend/pkg/crypto"
+)
+
+// captureStdout returns what run printed.
+func captureStdout(t *testing.T, run func() error) (string, error) {
+	t.Helper()
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("failed to create pipe: %v", err)
+	}
+	stdout := os.Stdout
+	os.Stdout = writer
+	runErr := run()
+	os.Stdout = stdout
+	writer.Close()
+
+	output, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("failed to read output: %v", err)
+	}
+	return string(output), runErr
+}
+
+func TestSign(t *testing.T) {
+	const (
+		secret  = "ops-secret"
+		qr      = `{"merchantId":"MERCHANT-1","partnerReferenceNo":"PARTNER-1","amount":{"value":"15000.00","currency":"IDR"}}`
+		payment = `{"originalReferenceNo":"A000000001","transactionStatusDesc":"SUCCESS","amount":{"value":"15000.00","currency":"IDR"}}`
+	)
+	dir := t.TempDir()
+	qrFile := filepath.Join(dir, "qr.json")
+	paymentFile := filepath.Join(dir, "payment.json")
+	brokenFile := filepath.Join(dir, "broken.json")
+	for path, body := range map[string]string{qrFile: qr, paymentFile: payment, brokenFile: "not json"} {
+		if err := os.WriteFile(path, []byte(body), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		args    []string
+		want    string
+		wantErr string
+	}{
+		{"raw body", []string{qrFile}, crypto.GenerateSignature(qr, secret), ""},
+		{"legacy qr", []string{"-legacy", "qr", qrFile}, crypto.GenerateSignature("MERCHANT-1|15000.00|PARTNER-1", secret), ""},
+		{"legacy payment", []string{"-legacy", "payment", paymentFile}, crypto.GenerateSignature("A000000001|15000.00|SUCCESS", secret), ""},
+		{"secret flag", []string{"-secret", "other", qrFile}, crypto.GenerateSignature(qr, "other"), ""},
+		{"unknown legacy kind", []string{"-legacy", "refund", qrFile}, "", `-legacy must be qr or payment, got "refund"`},
+		{"legacy body that is not JSON", []string{"-legacy", "qr", brokenFile}, "", "failed to decode QR request"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, err := captureStdout(t, func() error { return runSign(tt.args, secret, "") })
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("runSign() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("runSign() error = %v", err)
+			}
+			if got := strings.TrimSpace(output); got != tt.want {
+				t.Errorf("signature = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSignRequiresASecret(t *testing.T) {
+	if err := runSign([]string{"-legacy", "qr"}, "", "feed-secret"); err == nil || !strings.Contains(err.Error(), "secret is required") {
+		t.Errorf("runSign() error = %v, want a missing secret error", err)
+	}
+	if err := runSign([]string{"-feed", "MERCHANT-1"}, "secret", ""); err == nil || !strings.Contains(err.Error(), "feed secret") {
+		t.Errorf("runSign(-feed) error = %v, want a missing feed secret error", err)
+	}
+}
@@ -0,0 +1,233 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"payment-gateway-manjo/backend/internal/domain/entity"
+	"payment-gateway-manjo/backend/internal/infrastructure/config"
+	"payment-gateway-manjo/backend/internal/infrastructure/database"
+	"payment-gateway-manjo/backend/internal/usecase"
+)
+
+const txUsage = `usage: gatewayctl tx <get|list|history|expire|mark-failed> [arguments]`
+
+func runTx(cfg *config.Config, args []string) error {
+	if len(args) == 0 {
+		return errors.New(txUsage)
+	}
+	ctx := context.Background()
+
+	run, ok := map[string]func(context.Context, usecase.PaymentUsecase, *config.Config, []string) error{
+		"get":         txGet,
+		"list":        txList,
+		"history":     txHistory,
+		"expire":      txExpire,
+		"mark-failed": txMarkFailed,
+	}[args[0]]
+	if !ok {
+		return errors.New(txUsage)
+	}
+
+	paymentUsecase, closeDB, err := openPaymentUsecase(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+	return run(ctx, paymentUsecase, cfg, args[1:])
+}
+
+// openPaymentUsecase connects the way the API server does and refuses to
+// touch a schema the server would not start against either.
+func openPaymentUsecase(ctx context.Context, cfg *config.Config) (usecase.PaymentUsecase, func(), error) {
+	db, err := database.NewPostgresDB(cfg)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get database handle: %w", err)
+	}
+	closeDB := func() { sqlDB.Close() }
+
+	migrator, err := database.NewMigrator(db)
+	if err != nil {
+		closeDB()
+		return nil, nil, fmt.Errorf("failed to load migrations: %w", err)
+	}
+	if err := migrator.CheckUpToDate(ctx); err != nil {
+		closeDB()
+		return nil, nil, err
+	}
+
+	// Nothing in this process subscribes, but with the postgres broker the
+	// API replicas hear about changes made here.
+	transactor := database.NewTransactor(db, nil)
+	if cfg.Realtime.Broker == config.BrokerPostgres {
+		transactor = database.NewNotifyingTransactor(db, cfg.Realtime.Channel)
+	}
+
+	timeouts := usecase.Timeouts{
+		ProcessPayment: cfg.Timeouts.ProcessPayment,
+		Query:          cfg.Timeouts.Query,
+		ExpireBatch:    cfg.Timeouts.ExpireBatch,
+	}
+	return usecase.NewPaymentUsecase(
+		database.NewTransactionRepository(db, db),
+		database.NewTransactionEventRepository(db),
+		transactor,
+		timeouts,
+		cfg.Payment.PaidTimeMaxSkew,
+	), closeDB, nil
+}
+
+func txGet(ctx context.Context, u usecase.PaymentUsecase, _ *config.Config, args []string) error {
+	if len(args) != 1 {
+		return errors.New("usage: gatewayctl tx get <referenceNo>")
+	}
+	transactions, err := u.GetTransactions(ctx, "", "", args[0], "")
+	if err != nil {
+		return err
+	}
+	if len(transactions) == 0 {
+		return fmt.Errorf("transaction %s not found", args[0])
+	}
+	return printJSON(transactions[0])
+}
+
+func txList(ctx context.Context, u usecase.PaymentUsecase, _ *config.Config, args []string) error {
+	fs := flag.NewFlagSet("tx list", flag.ContinueOnError)
+	merchantID := fs.String("merchant", "", "only this merchant")
+	status := fs.String("status", "", "only this status")
+	partnerRefNo := fs.String("partner-ref", "", "only this partner reference")
+	asJSON := fs.Bool("json", false, "print JSON instead of a table")
+	if _, err := parseInterspersed(fs, args); err != nil {
+		return err
+	}
+
+	transactions, err := u.GetTransactions(ctx, *merchantID, *partnerRefNo, "", *status)
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		return printJSON(transactions)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "REFERENCE\tPARTNER REFERENCE\tMERCHANT\tAMOUNT\tSTATUS\tCREATED AT")
+	for _, t := range transactions {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%.2f %s\t%s\t%s\n",
+			t.ReferenceNumber, t.PartnerReferenceNumber, t.MerchantID, t.Amount, t.Currency, t.Status,
+			t.CreatedAt.Format("2006-01-02 15:04:05 MST"))
+	}
+	return w.Flush()
+}
+
+func txHistory(ctx context.Context, u usecase.PaymentUsecase, _ *config.Config, args []string) error {
+	fs := flag.NewFlagSet("tx history", flag.ContinueOnError)
+	asJSON := fs.Bool("json", false, "print JSON instead of a table")
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return errors.New("usage: gatewayctl tx history <referenceNo> [-json]")
+	}
+
+	events, err := u.GetTransactionHistory(ctx, positional[0])
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		return printJSON(events)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "AT\tFROM\tTO\tSOURCE\tACTOR\tREASON")
+	for _, e := range events {
+		from := e.OldStatus
+		if from == "" {
+			from = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			e.CreatedAt.Format("2006-01-02 15:04:05 MST"), from, e.NewStatus, e.Source, e.Actor, e.Reason)
+	}
+	return w.Flush()
+}
+
+// txExpire runs the expiry job once, batch after batch, instead of waiting
+// for the worker's next tick.
+func txExpire(ctx context.Context, u usecase.PaymentUsecase, cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("tx expire", flag.ContinueOnError)
+	olderThan := fs.Duration("older-than", cfg.Expiry.QRTTL, "expire pending transactions created longer ago than this")
+	limit := fs.Int("limit", cfg.Expiry.BatchSize, "transactions per batch")
+	if _, err := parseInterspersed(fs, args); err != nil {
+		return err
+	}
+	if *olderThan <= 0 || *limit <= 0 {
+		return errors.New("tx expire: -older-than and -limit must be positive")
+	}
+
+	cutoff := time.Now().Add(-*olderThan)
+	total := 0
+	for {
+		expired, err := u.ExpireTransactions(ctx, cutoff, *limit)
+		total += expired
+		if err != nil {
+			return fmt.Errorf("expired %d transactions before failing: %w", total, err)
+		}
+		if expired < *limit {
+			break
+		}
+	}
+	fmt.Printf("expired %d transactions created before %s\n", total, cutoff.Format(time.RFC3339))
+	return nil
+}
+
+func txMarkFailed(ctx context.Context, u usecase.PaymentUsecase, _ *config.Config, args []string) error {
+	fs := flag.NewFlagSet("tx mark-failed", flag.ContinueOnError)
+	reason := fs.String("reason", "", "why the transaction is failed by hand (required, kept in its history)")
+	actor := fs.String("actor", os.Getenv("USER"), "who is making the change (default $USER)")
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 || *reason == "" {
+		return errors.New("usage: gatewayctl tx mark-failed <referenceNo> -reason text [-actor name]")
+	}
+	if *actor == "" {
+		return errors.New("tx mark-failed: -actor is required when $USER is not set")
+	}
+
+	transaction, err := u.MarkFailed(ctx, positional[0], entity.AuditInfo{
+		Source: entity.SourceAdmin,
+		Actor:  *actor,
+		Reason: *reason,
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s is now %s\n", transaction.ReferenceNumber, transaction.Status)
+	return nil
+}
+
+// parseInterspersed parses flags that appear before or after positional
+// arguments, so "tx history A123 -json" works as well as the reverse.
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		if fs.NArg() == 0 {
+			return positional, nil
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+}
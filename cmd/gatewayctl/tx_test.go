@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"payment-gateway-manjo/backend/internal/infrastructure/config"
+	"payment-gateway-manjo/backend/internal/usecase"
+)
+
+func TestParseInterspersed(t *testing.T) {
+	tests := []struct {
+		name           string
+		args           []string
+		wantPositional []string
+		wantJSON       bool
+		wantActor      string
+	}{
+		{"flags first", []string{"-json", "-actor", "ops", "A1"}, []string{"A1"}, true, "ops"},
+		{"flags last", []string{"A1", "-json", "-actor", "ops"}, []string{"A1"}, true, "ops"},
+		{"flags between", []string{"A1", "-json", "A2", "-actor=ops", "A3"}, []string{"A1", "A2", "A3"}, true, "ops"},
+		{"no flags", []string{"A1", "A2"}, []string{"A1", "A2"}, false, ""},
+		{"terminator", []string{"A1", "--", "-json"}, []string{"A1", "-json"}, false, ""},
+		{"nothing", nil, nil, false, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := flag.NewFlagSet("test", flag.ContinueOnError)
+			asJSON := fs.Bool("json", false, "")
+			actor := fs.String("actor", "", "")
+
+			positional, err := parseInterspersed(fs, tt.args)
+			if err != nil {
+				t.Fatalf("parseInterspersed() error = %v", err)
+			}
+			if !reflect.DeepEqual(positional, tt.wantPositional) {
+				t.Errorf("positional = %q, want %q", positional, tt.wantPositional)
+			}
+			if *asJSON != tt.wantJSON || *actor != tt.wantActor {
+				t.Errorf("json = %v, actor = %q, want %v, %q", *asJSON, *actor, tt.wantJSON, tt.wantActor)
+			}
+		})
+	}
+}
+
+func TestParseInterspersedRejectsUnknownFlags(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(&strings.Builder{})
+	if _, err := parseInterspersed(fs, []string{"A1", "-verbose"}); err == nil {
+		t.Error("parseInterspersed() accepted an unknown flag")
+	}
+}
+
+// fakeExpirer expires up to limit of its pending transactions per call.
+type fakeExpirer struct {
+	usecase.PaymentUsecase
+	pending int
+	failAt  int
+	limits  []int
+	cutoffs []time.Time
+}
+
+func (f *fakeExpirer) ExpireTransactions(ctx context.Context, cutoff time.Time, limit int) (int, error) {
+	f.limits = append(f.limits, limit)
+	f.cutoffs = append(f.cutoffs, cutoff)
+	if f.failAt > 0 && len(f.limits) == f.failAt {
+		return 0, errors.New("database went away")
+	}
+	expired := min(limit, f.pending)
+	f.pending -= expired
+	return expired, nil
+}
+
+func TestTxExpireBatches(t *testing.T) {
+	cfg := &config.Config{Expiry: config.ExpiryConfig{QRTTL: 15 * time.Minute, BatchSize: 100}}
+
+	tests := []struct {
+		name       string
+		args       []string
+		pending    int
+		failAt     int
+		wantCalls  int
+		wantLimit  int
+		wantOutput string
+		wantErr    string
+	}{
+		{"one partial batch", nil, 30, 0, 1, 100, "expired 30 transactions", ""},
+		{"several batches", []string{"-limit", "10"}, 25, 0, 3, 10, "expired 25 transactions", ""},
+		{"exact multiple ends on an empty batch", []string{"-limit", "10"}, 20, 0, 3, 10, "expired 20 transactions", ""},
+		{"nothing to expire", nil, 0, 0, 1, 100, "expired 0 transactions", ""},
+		{"failure reports progress", []string{"-limit", "10"}, 50, 3, 3, 10, "", "expired 20 transactions before failing"},
+		{"non-positive limit", []string{"-limit", "0"}, 10, 0, 0, 0, "", "must be positive"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeExpirer{pending: tt.pending, failAt: tt.failAt}
+			started := time.Now()
+			output, err := captureStdout(t, func() error {
+				return txExpire(context.Background(), fake, cfg, tt.args)
+			})
+
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("txExpire() error = %v, want %q", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Fatalf("txExpire() error = %v", err)
+			}
+			if !strings.Contains(output, tt.wantOutput) {
+				t.Errorf("output = %q, want %q", output, tt.wantOutput)
+			}
+			if len(fake.limits) != tt.wantCalls {
+				t.Fatalf("ExpireTransactions called %d times, want %d", len(fake.limits), tt.wantCalls)
+			}
+			for i, limit := range fake.limits {
+				if limit != tt.wantLimit {
+					t.Errorf("batch %d limit = %d, want %d", i, limit, tt.wantLimit)
+				}
+				// Every batch uses the cutoff computed once at the start.
+				if !fake.cutoffs[i].Equal(fake.cutoffs[0]) {
+					t.Errorf("batch %d cutoff %v differs from the first %v", i, fake.cutoffs[i], fake.cutoffs[0])
+				}
+			}
+			if len(fake.cutoffs) > 0 {
+				if want := started.Add(-cfg.Expiry.QRTTL); fake.cutoffs[0].Before(want.Add(-time.Second)) || fake.cutoffs[0].After(want.Add(time.Second)) {
+					t.Errorf("cutoff = %v, want about %v", fake.cutoffs[0], want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"payment-gateway-manjo/backend/internal/delivery/http/dto"
+	"payment-gateway-manjo/backend/pkg/crypto"
+)
+
+// acquirer talks to the gateway the way a bank does: it signs every body it
+// sends with the shared secret.
+type acquirer struct {
+	baseURL         string
+	secretKey       string
+	legacySignature bool
+	client          *http.Client
+}
+
+func newAcquirer(baseURL, secretKey string, legacySignature bool, timeout time.Duration) *acquirer {
+	return &acquirer{
+		baseURL:         strings.TrimRight(baseURL, "/"),
+		secretKey:       secretKey,
+		legacySignature: legacySignature,
+		client:          &http.Client{Timeout: timeout},
+	}
+}
+
+// reply is the part of a gateway response the simulator reports on.
+type reply struct {
+	HTTPStatus            int
+	ResponseCode          string `json:"responseCode"`
+	ResponseMessage       string `json:"responseMessage"`
+	TransactionStatusDesc string `json:"transactionStatusDesc"`
+	ReferenceNo           string `json:"referenceNo"`
+	PartnerReferenceNo    string `json:"partnerReferenceNo"`
+	QRContent             string `json:"qrContent"`
+	Error                 string `json:"error"`
+}
+
+func (a *acquirer) generateQR(ctx context.Context, request dto.GenerateQRRequest) (*reply, error) {
+	return a.post(ctx, "/api/v1/qr/generate", &request)
+}
+
+func (a *acquirer) notify(ctx context.Context, request dto.PaymentNotificationRequest) (*reply, error) {
+	return a.post(ctx, "/api/v1/qr/payment", &request)
+}
+
+// partnerReferenceNo looks up the partner reference a QR was issued for; the
+// QR content itself only carries the gateway's reference.
+func (a *acquirer) partnerReferenceNo(ctx context.Context, referenceNo string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet,
+		a.baseURL+"/api/v1/transactions?referenceNo="+url.QueryEscape(referenceNo), nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to query transaction: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		ResponseCode string `json:"responseCode"`
+		Data         []struct {
+			PartnerReferenceNumber string `json:"partner_reference_number"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("failed to decode transaction query: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK || len(body.Data) == 0 {
+		return "", fmt.Errorf("transaction %s not found (HTTP %d, %s)", referenceNo, resp.StatusCode, body.ResponseCode)
+	}
+	return body.Data[0].PartnerReferenceNumber, nil
+}
+
+type signable interface {
+	LegacySignatureString() string
+}
+
+func (a *acquirer) post(ctx context.Context, path string, request signable) (*reply, error) {
+	raw, err := json.Marshal(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode request: %w", err)
+	}
+	signed := string(raw)
+	if a.legacySignature {
+		signed = request.LegacySignatureString()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.baseURL+path, bytes.NewReader(raw))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Signature", crypto.GenerateSignature(signed, a.secretKey))
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send %s: %w", path, err)
+	}
+	defer resp.Body.Close()
+
+	payload, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	result := &reply{HTTPStatus: resp.StatusCode}
+	if err := json.Unmarshal(payload, result); err != nil {
+		return nil, fmt.Errorf("unexpected response (HTTP %d): %s", resp.StatusCode, strings.TrimSpace(string(payload)))
+	}
+	return result, nil
+}
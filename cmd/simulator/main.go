@@ -0,0 +1,317 @@
+// Command simulator plays the QRIS acquirer for local end-to-end testing. It
+// reads QR content the gateway generated, decodes the amount and reference,
+// and after a delay sends the signed payment notification a bank would.
+//
+//	simulator -secret "$SECRET_KEY" "<qr content>"
+//	simulator -scenario duplicate < qrs.txt
+//	simulator -scenario burst -merchant MERCHANT-1 -amount 15000.00
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"os"
+	"os/signal"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"payment-gateway-manjo/backend/internal/delivery/http/dto"
+	"payment-gateway-manjo/backend/internal/domain/entity"
+	"payment-gateway-manjo/backend/pkg/qris"
+	"payment-gateway-manjo/backend/pkg/snaptime"
+
+	"github.com/google/uuid"
+)
+
+// job is one QR to pay. partnerReferenceNo is known up front for QRs the
+// simulator generated itself and looked up otherwise.
+type job struct {
+	content            string
+	partnerReferenceNo string
+}
+
+// outcome is the result of one delivered notification.
+type outcome struct {
+	referenceNo string
+	code        string
+	err         error
+}
+
+func main() {
+	if err := run(os.Args[1:], os.Stdin, os.Stdout); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return
+		}
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, stdin io.Reader, stdout io.Writer) error {
+	fs := flag.NewFlagSet("simulator", flag.ContinueOnError)
+	var (
+		o               options
+		baseURL         = fs.String("url", "http://localhost:8080", "gateway base URL")
+		secretKey       = fs.String("secret", os.Getenv("SECRET_KEY"), "shared signing secret (default $SECRET_KEY)")
+		scenario        = fs.String("scenario", "success", "preset to run, see below")
+		legacySignature = fs.Bool("legacy-signature", false, "sign the old field string instead of the raw body")
+		timeout         = fs.Duration("timeout", 10*time.Second, "per-request HTTP timeout")
+		merchantID      = fs.String("merchant", "SIMULATOR", "merchant ID for generated QRs")
+		amount          = fs.String("amount", "10000.00", "amount for generated QRs")
+	)
+	fs.StringVar(&o.status, "status", entity.StatusSuccess, "transactionStatusDesc to report")
+	fs.DurationVar(&o.delay, "delay", 3*time.Second, "wait between scanning a QR and notifying")
+	fs.DurationVar(&o.jitter, "jitter", 0, "random extra wait added to -delay")
+	fs.IntVar(&o.duplicates, "duplicates", 0, "extra deliveries of each notification")
+	fs.BoolVar(&o.parallelDuplicates, "parallel-duplicates", false, "send duplicates concurrently instead of one after another")
+	fs.Float64Var(&o.amountDelta, "amount-delta", 0, "added to the QR amount in the notification")
+	fs.DurationVar(&o.paidTimeOffset, "paid-time-offset", 0, "shift paidTime from the moment of sending")
+	fs.IntVar(&o.generate, "generate", 0, "generate this many QRs first instead of reading them")
+	fs.IntVar(&o.concurrency, "concurrency", 1, "QRs paid at the same time")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: simulator [flags] [qr content...]\n\nQR content is read from stdin, one per line, when none is given.\n\nFlags:\n")
+		fs.PrintDefaults()
+		fmt.Fprintf(fs.Output(), "\nScenarios:\n%s", scenarioUsage())
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := applyScenario(*scenario, &o, fs); err != nil {
+		return err
+	}
+	if *secretKey == "" {
+		return errors.New("a signing secret is required: set -secret or SECRET_KEY")
+	}
+	if o.concurrency < 1 {
+		o.concurrency = 1
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stderr, nil))
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	acq := newAcquirer(*baseURL, *secretKey, *legacySignature, *timeout)
+
+	var jobs []job
+	if o.generate > 0 {
+		generated, err := generate(ctx, acq, logger, o.generate, *merchantID, *amount)
+		if err != nil {
+			return err
+		}
+		jobs = generated
+	} else {
+		contents := fs.Args()
+		if len(contents) == 0 {
+			read, err := readLines(stdin)
+			if err != nil {
+				return err
+			}
+			contents = read
+		}
+		for _, content := range contents {
+			jobs = append(jobs, job{content: content})
+		}
+	}
+	if len(jobs) == 0 {
+		return errors.New("no QR content to pay")
+	}
+
+	logger.Info("paying QRs", "count", len(jobs), "scenario", *scenario, "status", o.status,
+		"delay", o.delay, "duplicates", o.duplicates, "concurrency", o.concurrency)
+
+	outcomes := payAll(ctx, acq, logger, o, jobs)
+	if check, ok := scenarioChecks[*scenario]; ok {
+		if warning := check(outcomes); warning != "" {
+			logger.Warn(warning, "scenario", *scenario)
+		}
+	}
+	return summarize(stdout, outcomes)
+}
+
+func generate(ctx context.Context, acq *acquirer, logger *slog.Logger, count int, merchantID, amount string) ([]job, error) {
+	jobs := make([]job, 0, count)
+	for i := 0; i < count; i++ {
+		request := dto.GenerateQRRequest{
+			MerchantID:         merchantID,
+			PartnerReferenceNo: "SIM-" + uuid.New().String()[:18],
+			Amount:             dto.Amount{Value: amount, Currency: qris.Currency},
+		}
+		result, err := acq.generateQR(ctx, request)
+		if err != nil {
+			return nil, err
+		}
+		if result.QRContent == "" {
+			return nil, fmt.Errorf("failed to generate QR: %s %s %s", result.ResponseCode, result.ResponseMessage, result.Error)
+		}
+		logger.Debug("generated QR", "reference_no", result.ReferenceNo)
+		jobs = append(jobs, job{content: result.QRContent, partnerReferenceNo: result.PartnerReferenceNo})
+	}
+	return jobs, nil
+}
+
+func payAll(ctx context.Context, acq *acquirer, logger *slog.Logger, o options, jobs []job) []outcome {
+	var (
+		mu       sync.Mutex
+		outcomes []outcome
+		wg       sync.WaitGroup
+	)
+	record := func(result outcome) {
+		mu.Lock()
+		outcomes = append(outcomes, result)
+		mu.Unlock()
+	}
+
+	slots := make(chan struct{}, o.concurrency)
+	for _, j := range jobs {
+		wg.Add(1)
+		slots <- struct{}{}
+		go func(j job) {
+			defer wg.Done()
+			defer func() { <-slots }()
+			pay(ctx, acq, logger, o, j, record)
+		}(j)
+	}
+	wg.Wait()
+	return outcomes
+}
+
+func pay(ctx context.Context, acq *acquirer, logger *slog.Logger, o options, j job, record func(outcome)) {
+	payload, err := qris.Decode(j.content)
+	if err != nil {
+		record(outcome{err: err})
+		logger.Error("cannot read QR", "error", err)
+		return
+	}
+	fail := func(err error) {
+		record(outcome{referenceNo: payload.ReferenceNo, err: err})
+		logger.Error("notification not sent", "reference_no", payload.ReferenceNo, "error", err)
+	}
+
+	partnerReferenceNo := j.partnerReferenceNo
+	if partnerReferenceNo == "" {
+		if partnerReferenceNo, err = acq.partnerReferenceNo(ctx, payload.ReferenceNo); err != nil {
+			fail(err)
+			return
+		}
+	}
+
+	amount := payload.Amount
+	if o.amountDelta != 0 {
+		value, err := strconv.ParseFloat(payload.Amount, 64)
+		if err != nil {
+			fail(err)
+			return
+		}
+		amount = fmt.Sprintf("%.2f", value+o.amountDelta)
+	}
+
+	wait := o.delay
+	if o.jitter > 0 {
+		wait += rand.N(o.jitter)
+	}
+	select {
+	case <-time.After(wait):
+	case <-ctx.Done():
+		fail(ctx.Err())
+		return
+	}
+
+	request := dto.PaymentNotificationRequest{
+		OriginalReferenceNo:        payload.ReferenceNo,
+		OriginalPartnerReferenceNo: partnerReferenceNo,
+		TransactionStatusDesc:      o.status,
+		PaidTime:                   snaptime.Format(time.Now().Add(o.paidTimeOffset)),
+		Amount:                     dto.Amount{Value: amount, Currency: qris.Currency},
+	}
+	send := func(attempt int) {
+		started := time.Now()
+		result, err := acq.notify(ctx, request)
+		if err != nil {
+			fail(err)
+			return
+		}
+		record(outcome{referenceNo: payload.ReferenceNo, code: result.ResponseCode})
+		logger.Info("notification sent",
+			"reference_no", payload.ReferenceNo,
+			"attempt", attempt,
+			"amount", amount,
+			"http_status", result.HTTPStatus,
+			"response_code", result.ResponseCode,
+			"transaction_status", result.TransactionStatusDesc,
+			"error", result.Error,
+			"latency", time.Since(started),
+		)
+	}
+
+	deliveries := 1 + o.duplicates
+	if !o.parallelDuplicates {
+		for attempt := 1; attempt <= deliveries; attempt++ {
+			send(attempt)
+		}
+		return
+	}
+	var wg sync.WaitGroup
+	for attempt := 1; attempt <= deliveries; attempt++ {
+		wg.Add(1)
+		go func(attempt int) {
+			defer wg.Done()
+			send(attempt)
+		}(attempt)
+	}
+	wg.Wait()
+}
+
+// summarize prints how many notifications ended with each response code. Error
+// codes are expected in most scenarios; only notifications that could not be
+// delivered at all make the run fail.
+func summarize(w io.Writer, outcomes []outcome) error {
+	counts := map[string]int{}
+	failed := 0
+	for _, result := range outcomes {
+		if result.err != nil {
+			counts["not delivered"]++
+			failed++
+			continue
+		}
+		counts[result.code]++
+	}
+
+	keys := make([]string, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Fprintf(w, "%-14s %d\n", key, counts[key])
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d notification(s) could not be delivered", failed)
+	}
+	return nil
+}
+
+func readLines(r io.Reader) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read QR content: %w", err)
+	}
+	return lines, nil
+}
@@ -0,0 +1,65 @@
+// Package dto defines the request bodies shared by the signature middleware,
+// which decodes and validates them, and the handlers that consume them.
+package dto
+
+import (
+	"payment-gateway-manjo/backend/pkg/crypto"
+
+	"github.com/gin-gonic/gin"
+)
+
+const requestKey = "dto.request"
+
+type Amount struct {
+	Value    string `json:"value" binding:"required,amount"`
+	Currency string `json:"currency" binding:"required,currency"`
+}
+
+type GenerateQRRequest struct {
+	MerchantID         string `json:"merchantId" binding:"required,merchantid"`
+	PartnerReferenceNo string `json:"partnerReferenceNo" binding:"required"`
+	Amount             Amount `json:"amount"`
+}
+
+// LegacySignatureString is what partners signed before signatures covered the
+// whole body.
+func (r *GenerateQRRequest) LegacySignatureString() string {
+	return crypto.GenerateQRSignatureString(r.MerchantID, r.Amount.Value, r.PartnerReferenceNo)
+}
+
+type PaymentNotificationRequest struct {
+	OriginalReferenceNo        string `json:"originalReferenceNo" binding:"required"`
+	OriginalPartnerReferenceNo string `json:"originalPartnerReferenceNo" binding:"required"`
+	TransactionStatusDesc      string `json:"transactionStatusDesc" binding:"required,oneof=SUCCESS FAILED"`
+	PaidTime                   string `json:"paidTime" binding:"required,rfc3339"`
+	Amount                     Amount `json:"amount"`
+}
+
+// LegacySignatureString is what acquirers signed before signatures covered
+// the whole body.
+func (r *PaymentNotificationRequest) LegacySignatureString() string {
+	return crypto.GeneratePaymentSignatureString(r.OriginalReferenceNo, r.Amount.Value, r.TransactionStatusDesc)
+}
+
+// SetRequest stores the verified and validated body for the handler.
+func SetRequest(c *gin.Context, request interface{}) {
+	c.Set(requestKey, request)
+}
+
+func GenerateQR(c *gin.Context) (*GenerateQRRequest, bool) {
+	request, ok := c.Get(requestKey)
+	if !ok {
+		return nil, false
+	}
+	typed, ok := request.(*GenerateQRRequest)
+	return typed, ok
+}
+
+func PaymentNotification(c *gin.Context) (*PaymentNotificationRequest, bool) {
+	request, ok := c.Get(requestKey)
+	if !ok {
+		return nil, false
+	}
+	typed, ok := request.(*PaymentNotificationRequest)
+	return typed, ok
+}
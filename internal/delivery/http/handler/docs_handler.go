@@ -0,0 +1,25 @@
+package handler
+
+import (
+	"net/http"
+
+	"payment-gateway-manjo/backend/internal/delivery/http/openapi"
+
+	"github.com/gin-gonic/gin"
+)
+
+type DocsHandler struct{}
+
+func NewDocsHandler() *DocsHandler {
+	return &DocsHandler{}
+}
+
+func (h *DocsHandler) Spec(c *gin.Context) {
+	c.Data(http.StatusOK, "application/json", openapi.JSON())
+}
+
+// UI serves a self-contained page that renders the spec, so the docs work
+// without reaching any CDN.
+func (h *DocsHandler) UI(c *gin.Context) {
+	c.Data(http.StatusOK, "text/html; charset=utf-8", openapi.DocsPage)
+}
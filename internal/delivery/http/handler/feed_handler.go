@@ -0,0 +1,222 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"payment-gateway-manjo/backend/internal/domain/entity"
+	"payment-gateway-manjo/backend/internal/domain/pubsub"
+	"payment-gateway-manjo/backend/internal/infrastructure/metrics"
+	"payment-gateway-manjo/backend/internal/usecase"
+	"payment-gateway-manjo/backend/pkg/response"
+	"payment-gateway-manjo/backend/pkg/snap"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+// Feed message types.
+const (
+	FeedStatus    = "status"
+	FeedReady     = "ready"
+	FeedHeartbeat = "heartbeat"
+)
+
+const (
+	// feedReplayPage is how many missed changes are loaded per query while a
+	// reconnecting client catches up.
+	feedReplayPage = 100
+	// feedWriteWait bounds every write, so a client that stops reading is
+	// disconnected instead of holding the handler.
+	feedWriteWait = 10 * time.Second
+	// feedReadLimit is generous for the close and pong frames clients send;
+	// the feed takes no commands.
+	feedReadLimit = 512
+)
+
+// FeedMessage is one JSON text frame of a merchant feed. Status messages
+// carry a change; ready follows the replay of missed changes with the last
+// event ID sent; heartbeats let clients notice a dead connection.
+type FeedMessage struct {
+	Type string               `json:"type"`
+	ID   uint                 `json:"id,omitempty"`
+	Data *entity.StatusChange `json:"data,omitempty"`
+}
+
+type FeedHandler struct {
+	paymentUsecase usecase.PaymentUsecase
+	subscriber     pubsub.Subscriber
+	heartbeat      time.Duration
+	upgrader       websocket.Upgrader
+}
+
+func NewFeedHandler(paymentUsecase usecase.PaymentUsecase, subscriber pubsub.Subscriber, heartbeat time.Duration, allowedOrigins []string) *FeedHandler {
+	return &FeedHandler{
+		paymentUsecase: paymentUsecase,
+		subscriber:     subscriber,
+		heartbeat:      heartbeat,
+		upgrader:       websocket.Upgrader{CheckOrigin: checkOrigin(allowedOrigins)},
+	}
+}
+
+// MerchantFeed streams every status change of the merchant's transactions
+// over a WebSocket. A client that reconnects with lastEventId, or the
+// Last-Event-ID header, first receives the changes it missed.
+func (h *FeedHandler) MerchantFeed(c *gin.Context) {
+	merchantID := c.Param("merchantId")
+
+	lastID, err := lastEventID(c)
+	if err != nil {
+		response.Error(c, snap.ServiceQuery, snap.CaseInvalidFieldFormat, "lastEventId must be a non-negative integer")
+		return
+	}
+
+	// Subscribe before replaying so nothing committed during the replay is
+	// lost; changes the replay already sent are skipped below.
+	subscription := h.subscriber.Subscribe(pubsub.Filter{MerchantID: merchantID})
+	defer subscription.Close()
+
+	conn, err := h.upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		// The upgrader has already answered the request.
+		return
+	}
+	defer conn.Close()
+
+	metrics.RealtimeConnections.WithLabelValues(metrics.TransportWebSocket).Inc()
+	defer metrics.RealtimeConnections.WithLabelValues(metrics.TransportWebSocket).Dec()
+
+	closed := h.readPump(conn)
+
+	if lastID > 0 {
+		if lastID, err = h.replay(c, conn, merchantID, lastID); err != nil {
+			closeFeed(conn, websocket.CloseInternalServerErr, "failed to load missed changes")
+			return
+		}
+	}
+	if err := writeFeed(conn, FeedMessage{Type: FeedReady, ID: lastID}); err != nil {
+		return
+	}
+
+	ticker := time.NewTicker(h.heartbeat)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-closed:
+			return
+		case <-ticker.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(feedWriteWait)); err != nil {
+				return
+			}
+			if err := writeFeed(conn, FeedMessage{Type: FeedHeartbeat}); err != nil {
+				return
+			}
+		case change, ok := <-subscription.Events():
+			if !ok {
+				// Changes may have been missed either way; the client resumes
+				// from the last one it received.
+				if err := subscription.Err(); err != nil {
+					if errors.Is(err, pubsub.ErrSlowConsumer) {
+						metrics.RealtimeDropped.WithLabelValues(metrics.TransportWebSocket).Inc()
+					}
+					closeFeed(conn, websocket.CloseTryAgainLater, err.Error()+"; reconnect with lastEventId")
+				}
+				return
+			}
+			if change.EventID <= lastID {
+				continue
+			}
+			lastID = change.EventID
+			if err := writeFeed(conn, FeedMessage{Type: FeedStatus, ID: change.EventID, Data: &change}); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// replay sends the merchant's changes after lastID and returns the ID of the
+// last one sent.
+func (h *FeedHandler) replay(c *gin.Context, conn *websocket.Conn, merchantID string, lastID uint) (uint, error) {
+	for {
+		changes, err := h.paymentUsecase.GetStatusChanges(c.Request.Context(), merchantID, lastID, feedReplayPage)
+		if err != nil {
+			return lastID, err
+		}
+		for i := range changes {
+			if err := writeFeed(conn, FeedMessage{Type: FeedStatus, ID: changes[i].EventID, Data: &changes[i]}); err != nil {
+				return lastID, err
+			}
+			lastID = changes[i].EventID
+		}
+		if len(changes) < feedReplayPage {
+			return lastID, nil
+		}
+	}
+}
+
+// readPump consumes what the client sends, which keeps pong and close frames
+// flowing, and closes the returned channel once the connection is gone or
+// has missed two heartbeats.
+func (h *FeedHandler) readPump(conn *websocket.Conn) <-chan struct{} {
+	closed := make(chan struct{})
+	conn.SetReadLimit(feedReadLimit)
+	_ = conn.SetReadDeadline(time.Now().Add(2 * h.heartbeat))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(2 * h.heartbeat))
+	})
+
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+	return closed
+}
+
+func writeFeed(conn *websocket.Conn, message FeedMessage) error {
+	_ = conn.SetWriteDeadline(time.Now().Add(feedWriteWait))
+	return conn.WriteJSON(message)
+}
+
+func closeFeed(conn *websocket.Conn, code int, reason string) {
+	_ = conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, reason), time.Now().Add(feedWriteWait))
+}
+
+func lastEventID(c *gin.Context) (uint, error) {
+	value := c.Query("lastEventId")
+	if value == "" {
+		value = c.GetHeader("Last-Event-ID")
+	}
+	if value == "" {
+		return 0, nil
+	}
+	id, err := strconv.ParseUint(value, 10, 64)
+	return uint(id), err
+}
+
+// checkOrigin admits browsers from the origins CORS allows, and from the
+// API's own host when none are configured. Clients that send no Origin, i.e.
+// anything but a browser, are authenticated by signature alone.
+func checkOrigin(allowedOrigins []string) func(r *http.Request) bool {
+	return func(r *http.Request) bool {
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			return true
+		}
+		for _, allowed := range allowedOrigins {
+			if allowed == "*" || strings.EqualFold(allowed, origin) {
+				return true
+			}
+		}
+		u, err := url.Parse(origin)
+		return err == nil && strings.EqualFold(u.Host, r.Host)
+	}
+}
@@ -0,0 +1,38 @@
+package handler
+
+import (
+	"net/http"
+
+	"payment-gateway-manjo/backend/internal/infrastructure/health"
+
+	"github.com/gin-gonic/gin"
+)
+
+type HealthHandler struct {
+	probe *health.Probe
+}
+
+func NewHealthHandler(probe *health.Probe) *HealthHandler {
+	return &HealthHandler{
+		probe: probe,
+	}
+}
+
+// Livez only reports that the process is able to serve HTTP.
+func (h *HealthHandler) Livez(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": health.StatusUp})
+}
+
+func (h *HealthHandler) Readyz(c *gin.Context) {
+	ready, components := h.probe.Ready(c.Request.Context())
+
+	status, code := health.StatusUp, http.StatusOK
+	if !ready {
+		status, code = health.StatusDown, http.StatusServiceUnavailable
+	}
+
+	c.JSON(code, gin.H{
+		"status":     status,
+		"components": components,
+	})
+}
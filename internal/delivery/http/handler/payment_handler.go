@@ -1,11 +1,18 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
+	"payment-gateway-manjo/backend/internal/delivery/http/dto"
+	"payment-gateway-manjo/backend/internal/domain/domainerr"
+	"payment-gateway-manjo/backend/internal/domain/entity"
+	"payment-gateway-manjo/backend/internal/infrastructure/metrics"
 	"payment-gateway-manjo/backend/internal/usecase"
+	"payment-gateway-manjo/backend/pkg/crypto"
 	"payment-gateway-manjo/backend/pkg/response"
+	"payment-gateway-manjo/backend/pkg/snap"
 
 	"github.com/gin-gonic/gin"
 )
@@ -20,17 +27,6 @@ func NewPaymentHandler(paymentUsecase usecase.PaymentUsecase) *PaymentHandler {
 	}
 }
 
-type PaymentNotificationRequest struct {
-	OriginalReferenceNo        string `json:"originalReferenceNo" binding:"required"`
-	OriginalPartnerReferenceNo string `json:"originalPartnerReferenceNo" binding:"required"`
-	TransactionStatusDesc      string `json:"transactionStatusDesc" binding:"required"`
-	PaidTime                   string `json:"paidTime" binding:"required"`
-	Amount                     struct {
-		Value    string `json:"value" binding:"required"`
-		Currency string `json:"currency" binding:"required"`
-	} `json:"amount" binding:"required"`
-}
-
 type PaymentNotificationResponse struct {
 	ResponseCode          string `json:"responseCode"`
 	ResponseMessage       string `json:"responseMessage"`
@@ -38,48 +34,46 @@ type PaymentNotificationResponse struct {
 }
 
 func (h *PaymentHandler) ProcessPayment(c *gin.Context) {
-	requestBodyInterface, exists := c.Get("requestBody")
-	if !exists {
-		response.Error(c, http.StatusBadRequest, response.CodeBadRequest, "Bad Request", "Invalid request body")
+	requestBody, ok := dto.PaymentNotification(c)
+	if !ok {
+		response.Error(c, snap.ServiceNotify, snap.CaseBadRequest, "Invalid request body")
 		return
 	}
 
-	requestBody := requestBodyInterface.(struct {
-		OriginalReferenceNo        string `json:"originalReferenceNo"`
-		OriginalPartnerReferenceNo string `json:"originalPartnerReferenceNo"`
-		TransactionStatusDesc      string `json:"transactionStatusDesc"`
-		PaidTime                   string `json:"paidTime"`
-		Amount                     struct {
-			Value    string `json:"value"`
-			Currency string `json:"currency"`
-		} `json:"amount"`
-	})
-
 	amount, err := strconv.ParseFloat(requestBody.Amount.Value, 64)
 	if err != nil {
-		response.Error(c, http.StatusBadRequest, response.CodeBadRequest, "Bad Request", "Invalid amount format")
+		response.Error(c, snap.ServiceNotify, snap.CaseInvalidFieldFormat, "Invalid amount format")
 		return
 	}
 
+	audit := entity.AuditInfo{
+		Source: entity.SourceAcquirerNotification,
+		Actor:  c.ClientIP(),
+	}
+	if rawBody, ok := c.Get(gin.BodyBytesKey); ok {
+		audit.PayloadHash = crypto.HashPayload(rawBody.([]byte))
+	}
+
 	transaction, err := h.paymentUsecase.ProcessPayment(
+		c.Request.Context(),
 		requestBody.OriginalReferenceNo,
 		amount,
 		requestBody.TransactionStatusDesc,
 		requestBody.PaidTime,
+		audit,
 	)
 
 	if err != nil {
-		if err.Error() == "transaction not found" {
-			response.Error(c, http.StatusNotFound, response.CodeNotFound, "Transaction Not Found", err.Error())
-			return
+		if errors.Is(err, domainerr.ErrAmountMismatch) {
+			metrics.AmountMismatches.Inc()
 		}
-		response.Error(c, http.StatusInternalServerError, response.CodeInternalError, "Internal Server Error", err.Error())
+		response.FromError(c, snap.ServiceNotify, err)
 		return
 	}
 
 	paymentResponse := PaymentNotificationResponse{
-		ResponseCode:          response.CodePaymentSuccess,
-		ResponseMessage:       "Successful",
+		ResponseCode:          snap.Code(snap.ServiceNotify, snap.CaseSuccessful),
+		ResponseMessage:       snap.CaseSuccessful.Message,
 		TransactionStatusDesc: transaction.Status,
 	}
 
@@ -91,15 +85,22 @@ func (h *PaymentHandler) GetTransactions(c *gin.Context) {
 	partnerRefNo := c.Query("partnerReferenceNo")
 	refNo := c.Query("referenceNo")
 	status := c.Query("status")
-	transactions, err := h.paymentUsecase.GetTransactions(merchantID, partnerRefNo, refNo, status)
+	transactions, err := h.paymentUsecase.GetTransactions(c.Request.Context(), merchantID, partnerRefNo, refNo, status)
 	if err != nil {
-		response.Error(c, http.StatusInternalServerError, response.CodeInternalError, "Internal Server Error", err.Error())
+		response.FromError(c, snap.ServiceQuery, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"responseCode":    "2000000",
-		"responseMessage": "Successful",
-		"data":            transactions,
-	})
-}
\ No newline at end of file
+	response.Success(c, snap.ServiceQuery, transactions)
+}
+
+func (h *PaymentHandler) GetTransactionHistory(c *gin.Context) {
+	referenceNo := c.Param("referenceNo")
+	events, err := h.paymentUsecase.GetTransactionHistory(c.Request.Context(), referenceNo)
+	if err != nil {
+		response.FromError(c, snap.ServiceQuery, err)
+		return
+	}
+
+	response.Success(c, snap.ServiceQuery, events)
+}
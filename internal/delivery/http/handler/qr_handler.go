@@ -4,8 +4,11 @@ import (
 	"net/http"
 	"strconv"
 
+	"payment-gateway-manjo/backend/internal/delivery/http/dto"
+	"payment-gateway-manjo/backend/internal/infrastructure/metrics"
 	"payment-gateway-manjo/backend/internal/usecase"
 	"payment-gateway-manjo/backend/pkg/response"
+	"payment-gateway-manjo/backend/pkg/snap"
 
 	"github.com/gin-gonic/gin"
 )
@@ -20,15 +23,6 @@ func NewQRHandler(qrUsecase usecase.QRGeneratorUsecase) *QRHandler {
 	}
 }
 
-type GenerateQRRequest struct {
-	MerchantID         string `json:"merchantId" binding:"required"`
-	PartnerReferenceNo string `json:"partnerReferenceNo" binding:"required"`
-	Amount             struct {
-		Value    string `json:"value" binding:"required"`
-		Currency string `json:"currency" binding:"required"`
-	} `json:"amount" binding:"required"`
-}
-
 type GenerateQRResponse struct {
 	ResponseCode       string `json:"responseCode"`
 	ResponseMessage    string `json:"responseMessage"`
@@ -38,33 +32,25 @@ type GenerateQRResponse struct {
 }
 
 func (h *QRHandler) GenerateQR(c *gin.Context) {
-	requestBodyInterface, exists := c.Get("requestBody")
-	if !exists {
-		response.Error(c, http.StatusBadRequest, response.CodeBadRequest, "Bad Request", "Invalid request body")
+	requestBody, ok := dto.GenerateQR(c)
+	if !ok {
+		response.Error(c, snap.ServiceGenerateQR, snap.CaseBadRequest, "Invalid request body")
 		return
 	}
 
-	requestBody := requestBodyInterface.(struct {
-		MerchantID        string `json:"merchantId"`
-		PartnerReferenceNo string `json:"partnerReferenceNo"`
-		Amount            struct {
-			Value    string `json:"value"`
-			Currency string `json:"currency"`
-		} `json:"amount"`
-	})
-
 	amount, err := strconv.ParseFloat(requestBody.Amount.Value, 64)
 	if err != nil {
-		response.Error(c, http.StatusBadRequest, response.CodeBadRequest, "Bad Request", "Invalid amount format")
+		response.Error(c, snap.ServiceGenerateQR, snap.CaseInvalidFieldFormat, "Invalid amount format")
 		return
 	}
 
 	if amount <= 0 {
-		response.Error(c, http.StatusBadRequest, response.CodeBadRequest, "Bad Request", "Amount must be greater than 0")
+		response.Error(c, snap.ServiceGenerateQR, snap.CaseInvalidFieldFormat, "Amount must be greater than 0")
 		return
 	}
 
 	transaction, err := h.qrUsecase.GenerateQR(
+		c.Request.Context(),
 		requestBody.MerchantID,
 		amount,
 		requestBody.Amount.Currency,
@@ -72,17 +58,19 @@ func (h *QRHandler) GenerateQR(c *gin.Context) {
 	)
 
 	if err != nil {
-		response.Error(c, http.StatusInternalServerError, response.CodeInternalError, "Internal Server Error", err.Error())
+		response.FromError(c, snap.ServiceGenerateQR, err)
 		return
 	}
 
+	metrics.QRGenerated.WithLabelValues(transaction.Currency).Inc()
+
 	qrResponse := GenerateQRResponse{
-		ResponseCode:       response.CodeSuccess,
-		ResponseMessage:    "Successful",
+		ResponseCode:       snap.Code(snap.ServiceGenerateQR, snap.CaseSuccessful),
+		ResponseMessage:    snap.CaseSuccessful.Message,
 		ReferenceNo:        transaction.ReferenceNumber,
 		PartnerReferenceNo: transaction.PartnerReferenceNumber,
 		QRContent:          transaction.QRContent,
 	}
 
 	c.JSON(http.StatusOK, qrResponse)
-}
\ No newline at end of file
+}
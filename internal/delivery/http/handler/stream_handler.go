@@ -0,0 +1,124 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"payment-gateway-manjo/backend/internal/domain/domainerr"
+	"payment-gateway-manjo/backend/internal/domain/entity"
+	"payment-gateway-manjo/backend/internal/domain/pubsub"
+	"payment-gateway-manjo/backend/internal/infrastructure/metrics"
+	"payment-gateway-manjo/backend/internal/usecase"
+	"payment-gateway-manjo/backend/pkg/response"
+	"payment-gateway-manjo/backend/pkg/snap"
+
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+)
+
+// EventStatus is the SSE event name for every status message.
+const EventStatus = "status"
+
+// reconnectDelay is the retry hint sent to EventSource clients. A stream that
+// ends early, e.g. because the client fell behind, is picked up again with a
+// fresh snapshot.
+const reconnectDelay = 3 * time.Second
+
+type StreamHandler struct {
+	paymentUsecase usecase.PaymentUsecase
+	subscriber     pubsub.Subscriber
+	heartbeat      time.Duration
+}
+
+func NewStreamHandler(paymentUsecase usecase.PaymentUsecase, subscriber pubsub.Subscriber, heartbeat time.Duration) *StreamHandler {
+	return &StreamHandler{
+		paymentUsecase: paymentUsecase,
+		subscriber:     subscriber,
+		heartbeat:      heartbeat,
+	}
+}
+
+// TransactionEvents streams a transaction's status as Server-Sent Events: the
+// current status first, then every change until it reaches a final status.
+// The merchantId query parameter has been authenticated by the signature
+// middleware; another merchant's transaction is answered as not found.
+func (h *StreamHandler) TransactionEvents(c *gin.Context) {
+	referenceNo := c.Param("referenceNo")
+	ctx := c.Request.Context()
+
+	// Subscribe before loading so a change committed in between is not lost;
+	// anything already covered by the snapshot is skipped below.
+	subscription := h.subscriber.Subscribe(pubsub.Filter{ReferenceNumber: referenceNo})
+	defer subscription.Close()
+
+	snapshot, err := h.paymentUsecase.GetStatusSnapshot(ctx, referenceNo)
+	if err != nil {
+		response.FromError(c, snap.ServiceQuery, err)
+		return
+	}
+	if snapshot.MerchantID != c.Query("merchantId") {
+		response.FromError(c, snap.ServiceQuery, domainerr.New(domainerr.ErrNotFound, "transaction not found"))
+		return
+	}
+
+	// The server's write timeout is meant for ordinary requests; a stream
+	// stays open until the transaction settles or the client leaves.
+	_ = http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{})
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	metrics.RealtimeConnections.WithLabelValues(metrics.TransportSSE).Inc()
+	defer metrics.RealtimeConnections.WithLabelValues(metrics.TransportSSE).Dec()
+
+	h.send(c, snapshot)
+	if snapshot.Final() {
+		return
+	}
+	lastID := snapshot.EventID
+
+	ticker := time.NewTicker(h.heartbeat)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := c.Writer.WriteString(": heartbeat\n\n"); err != nil {
+				return
+			}
+			c.Writer.Flush()
+		case change, ok := <-subscription.Events():
+			if !ok {
+				if errors.Is(subscription.Err(), pubsub.ErrSlowConsumer) {
+					metrics.RealtimeDropped.WithLabelValues(metrics.TransportSSE).Inc()
+				}
+				return
+			}
+			if change.EventID <= lastID {
+				continue
+			}
+			lastID = change.EventID
+			h.send(c, change)
+			if change.Final() {
+				return
+			}
+		}
+	}
+}
+
+func (h *StreamHandler) send(c *gin.Context, change entity.StatusChange) {
+	c.Render(-1, sse.Event{
+		Id:    strconv.FormatUint(uint64(change.EventID), 10),
+		Event: EventStatus,
+		Retry: uint(reconnectDelay / time.Millisecond),
+		Data:  change,
+	})
+	c.Writer.Flush()
+}
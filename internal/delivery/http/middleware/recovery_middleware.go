@@ -0,0 +1,26 @@
+package middleware
+
+import (
+	"io"
+	"log/slog"
+	"runtime/debug"
+
+	"payment-gateway-manjo/backend/pkg/response"
+	"payment-gateway-manjo/backend/pkg/snap"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Recovery turns panics into a 500 response and logs them through logger.
+// gin's own recovery dumps raw request headers, including X-Signature, so its
+// output is discarded.
+func Recovery(logger *slog.Logger) gin.HandlerFunc {
+	return gin.CustomRecoveryWithWriter(io.Discard, func(c *gin.Context, recovered any) {
+		logger.ErrorContext(c.Request.Context(), "panic recovered",
+			"panic", recovered,
+			"stack", string(debug.Stack()),
+		)
+		response.Error(c, snap.ServiceGeneric, snap.CaseInternalServerError, "")
+		c.Abort()
+	})
+}
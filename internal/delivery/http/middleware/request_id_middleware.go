@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"regexp"
+
+	"payment-gateway-manjo/backend/pkg/requestid"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// validRequestID limits client-supplied IDs to a safe charset and length so
+// they can be echoed into headers and logs verbatim.
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._-]{1,128}$`)
+
+// RequestID passes through a well-formed X-Request-ID header or generates
+// one, echoes it in the response and stores it in the request context.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(requestid.Header)
+		if !validRequestID.MatchString(id) {
+			id = uuid.NewString()
+		}
+
+		c.Header(requestid.Header, id)
+		c.Request = c.Request.WithContext(requestid.NewContext(c.Request.Context(), id))
+		c.Next()
+	}
+}
@@ -1,104 +1,213 @@
 package middleware
 
 import (
+	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
+	"time"
 
+	"payment-gateway-manjo/backend/internal/delivery/http/dto"
+	"payment-gateway-manjo/backend/internal/delivery/http/validation"
+	"payment-gateway-manjo/backend/internal/infrastructure/config"
+	"payment-gateway-manjo/backend/internal/infrastructure/metrics"
 	"payment-gateway-manjo/backend/pkg/crypto"
 	"payment-gateway-manjo/backend/pkg/response"
+	"payment-gateway-manjo/backend/pkg/snap"
+	"payment-gateway-manjo/backend/pkg/snaptime"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
+var tracer = otel.Tracer("payment-gateway-manjo/backend/internal/delivery/http/middleware")
+
+// signedRequest is a body that older clients signed field by field rather
+// than as raw bytes.
+type signedRequest interface {
+	LegacySignatureString() string
+}
+
 type SignatureValidator struct {
-	secretKey string
+	secretKey           string
+	secretKeyNext       string
+	feedSecret          string
+	legacySignatures    bool
+	maxBodyBytes        int64
+	feedSignatureMaxAge time.Duration
 }
 
-func NewSignatureValidator(secretKey string) *SignatureValidator {
+func NewSignatureValidator(cfg config.SecurityConfig) *SignatureValidator {
 	return &SignatureValidator{
-		secretKey: secretKey,
+		secretKey:           cfg.SecretKey,
+		secretKeyNext:       cfg.SecretKeyNext,
+		feedSecret:          cfg.FeedSecret,
+		legacySignatures:    cfg.LegacySignatures,
+		maxBodyBytes:        cfg.MaxBodyBytes,
+		feedSignatureMaxAge: cfg.FeedSignatureMaxAge,
 	}
 }
 
 func (sv *SignatureValidator) ValidateQRSignature() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		receivedSignature := c.GetHeader("X-Signature")
-		if receivedSignature == "" {
-			response.Error(c, http.StatusUnauthorized, response.CodeUnauthorized, "Unauthorized", "Missing signature")
-			c.Abort()
-			return
-		}
+		sv.verify(c, "SignatureValidator.ValidateQRSignature", snap.ServiceGenerateQR, &dto.GenerateQRRequest{})
+	}
+}
 
-		var requestBody struct {
-			MerchantID        string `json:"merchantId"`
-			PartnerReferenceNo string `json:"partnerReferenceNo"`
-			Amount            struct {
-				Value    string `json:"value"`
-				Currency string `json:"currency"`
-			} `json:"amount"`
-		}
+func (sv *SignatureValidator) ValidatePaymentSignature() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		sv.verify(c, "SignatureValidator.ValidatePaymentSignature", snap.ServiceNotify, &dto.PaymentNotificationRequest{})
+	}
+}
 
-		if err := c.ShouldBindJSON(&requestBody); err != nil {
-			response.Error(c, http.StatusBadRequest, response.CodeBadRequest, "Bad Request", err.Error())
-			c.Abort()
-			return
-		}
+// ValidateFeedSignature authenticates a merchant's live feed with the
+// merchant's own key, derived from the feed secret. Browsers cannot set
+// headers on a WebSocket handshake, so the signature over merchant ID and
+// timestamp may also come in the query string; the timestamp keeps a leaked
+// URL from being usable for long.
+func (sv *SignatureValidator) ValidateFeedSignature() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		merchantID := c.Param("merchantId")
+		sv.verifyMerchant(c, "SignatureValidator.ValidateFeedSignature", merchantID, func(timestamp string) string {
+			return crypto.GenerateFeedSignatureString(merchantID, timestamp)
+		})
+	}
+}
+
+// ValidateStreamSignature authenticates a transaction's status stream the
+// same way, with the merchantId query parameter naming whose key signed
+// `referenceNo|timestamp`. EventSource cannot set headers either. The handler
+// checks that the transaction belongs to that merchant.
+func (sv *SignatureValidator) ValidateStreamSignature() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		referenceNo := c.Param("referenceNo")
+		sv.verifyMerchant(c, "SignatureValidator.ValidateStreamSignature", c.Query("merchantId"), func(timestamp string) string {
+			return crypto.GenerateStreamSignatureString(referenceNo, timestamp)
+		})
+	}
+}
 
-		signatureString := crypto.GenerateQRSignatureString(
-			requestBody.MerchantID,
-			requestBody.Amount.Value,
-			requestBody.PartnerReferenceNo,
-		)
+// verifyMerchant checks a timestamped signature made with merchantID's key
+// over signatureString(timestamp).
+func (sv *SignatureValidator) verifyMerchant(c *gin.Context, spanName, merchantID string, signatureString func(timestamp string) string) {
+	_, span := tracer.Start(c.Request.Context(), spanName)
 
-		if !crypto.ValidateSignature(signatureString, receivedSignature, sv.secretKey) {
-			response.Error(c, http.StatusUnauthorized, response.CodeUnauthorized, "Unauthorized", "Invalid signature")
-			c.Abort()
-			return
-		}
+	signature := c.GetHeader("X-Signature")
+	if signature == "" {
+		signature = c.Query("signature")
+	}
+	timestamp := c.Query("timestamp")
+	if merchantID == "" || signature == "" || timestamp == "" {
+		reject(c, span, metrics.SignatureMissing, snap.ServiceQuery, snap.CaseUnauthorized, "Missing merchantId, signature or timestamp")
+		return
+	}
 
-		c.Set("requestBody", requestBody)
-		c.Next()
+	signedAt, err := snaptime.Parse(timestamp)
+	if err != nil {
+		reject(c, span, metrics.SignatureMalformed, snap.ServiceQuery, snap.CaseInvalidFieldFormat, err.Error())
+		return
 	}
+	if age := time.Since(signedAt); age > sv.feedSignatureMaxAge || age < -sv.feedSignatureMaxAge {
+		reject(c, span, metrics.SignatureExpired, snap.ServiceQuery, snap.CaseUnauthorized, "Signature timestamp is too old or in the future")
+		return
+	}
+
+	if !crypto.ValidateSignature(signatureString(timestamp), signature, crypto.DeriveMerchantKey(merchantID, sv.feedSecret)) {
+		reject(c, span, metrics.SignatureInvalid, snap.ServiceQuery, snap.CaseUnauthorized, "Invalid signature")
+		return
+	}
+
+	span.End()
+	c.Next()
 }
 
-func (sv *SignatureValidator) ValidatePaymentSignature() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		receivedSignature := c.GetHeader("X-Signature")
-		if receivedSignature == "" {
-			response.Error(c, http.StatusUnauthorized, response.CodeUnauthorized, "Unauthorized", "Missing signature")
-			c.Abort()
+// verify reads the body once, checks X-Signature against the raw bytes and
+// only then decodes and validates it into request, which handlers retrieve
+// through the dto accessors.
+func (sv *SignatureValidator) verify(c *gin.Context, spanName string, service snap.ServiceCode, request signedRequest) {
+	_, span := tracer.Start(c.Request.Context(), spanName)
+
+	receivedSignature := c.GetHeader("X-Signature")
+	if receivedSignature == "" {
+		reject(c, span, metrics.SignatureMissing, service, snap.CaseUnauthorized, "Missing signature")
+		return
+	}
+
+	rawBody, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, sv.maxBodyBytes))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			reject(c, span, metrics.SignatureMalformed, service, snap.CaseBadRequest, "Request body too large")
 			return
 		}
+		reject(c, span, metrics.SignatureMalformed, service, snap.CaseBadRequest, "Failed to read request body")
+		return
+	}
+	c.Set(gin.BodyBytesKey, rawBody)
 
-		var requestBody struct {
-			OriginalReferenceNo        string `json:"originalReferenceNo"`
-			OriginalPartnerReferenceNo string `json:"originalPartnerReferenceNo"`
-			TransactionStatusDesc      string `json:"transactionStatusDesc"`
-			PaidTime                   string `json:"paidTime"`
-			Amount                     struct {
-				Value    string `json:"value"`
-				Currency string `json:"currency"`
-			} `json:"amount"`
+	if !sv.signedWithAnyKey(string(rawBody), receivedSignature) {
+		if !sv.legacySignatures {
+			reject(c, span, metrics.SignatureInvalid, service, snap.CaseUnauthorized, "Invalid signature")
+			return
 		}
-
-		if err := c.ShouldBindJSON(&requestBody); err != nil {
-			response.Error(c, http.StatusBadRequest, response.CodeBadRequest, "Bad Request", err.Error())
-			c.Abort()
+		// Older clients sign a few fields instead of the body, so the body
+		// has to be decoded before the signature can be checked. The caller
+		// is not authenticated yet, so a body that does not decode gets the
+		// same answer as a wrong signature.
+		if err := json.Unmarshal(rawBody, request); err != nil ||
+			!sv.signedWithAnyKey(request.LegacySignatureString(), receivedSignature) {
+			reject(c, span, metrics.SignatureInvalid, service, snap.CaseUnauthorized, "Invalid signature")
 			return
 		}
+		metrics.LegacySignatures.Inc()
+	}
 
-		signatureString := crypto.GeneratePaymentSignatureString(
-			requestBody.OriginalReferenceNo,
-			requestBody.Amount.Value,
-			requestBody.TransactionStatusDesc,
-		)
+	if err := c.ShouldBindBodyWith(request, binding.JSON); err != nil {
+		rejectBody(c, span, service, err)
+		return
+	}
 
-		if !crypto.ValidateSignature(signatureString, receivedSignature, sv.secretKey) {
-			response.Error(c, http.StatusUnauthorized, response.CodeUnauthorized, "Unauthorized", "Invalid signature")
-			c.Abort()
-			return
-		}
+	span.End()
+	dto.SetRequest(c, request)
+	c.Next()
+}
+
+// signedWithAnyKey reports whether signature is the HMAC of data under
+// SECRET_KEY or, during a rotation, SECRET_KEY_NEXT.
+func (sv *SignatureValidator) signedWithAnyKey(data, signature string) bool {
+	if crypto.ValidateSignature(data, signature, sv.secretKey) {
+		return true
+	}
+	if sv.secretKeyNext != "" && crypto.ValidateSignature(data, signature, sv.secretKeyNext) {
+		metrics.NextKeySignatures.Inc()
+		return true
+	}
+	return false
+}
 
-		c.Set("requestBody", requestBody)
-		c.Next()
+// rejectBody answers a body that failed to bind: field-level details for
+// validation failures, the decoder error for malformed JSON.
+func rejectBody(c *gin.Context, span trace.Span, service snap.ServiceCode, err error) {
+	fieldErrors, snapCase, ok := validation.FieldErrors(err)
+	if !ok {
+		reject(c, span, metrics.SignatureMalformed, service, snap.CaseBadRequest, err.Error())
+		return
 	}
-}
\ No newline at end of file
+	span.SetStatus(codes.Error, "validation failed")
+	span.End()
+	response.ValidationError(c, service, snapCase, fieldErrors)
+	c.Abort()
+}
+
+// reject records why a signed request was refused, ends the validation span
+// and aborts the chain with an error response.
+func reject(c *gin.Context, span trace.Span, reason string, service snap.ServiceCode, snapCase snap.Case, detail string) {
+	metrics.SignatureFailures.WithLabelValues(reason).Inc()
+	span.SetStatus(codes.Error, reason)
+	span.End()
+	response.Error(c, service, snapCase, detail)
+	c.Abort()
+}
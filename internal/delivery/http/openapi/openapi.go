@@ -0,0 +1,559 @@
+// Package openapi describes the HTTP API as an OpenAPI 3 document. The SNAP
+// response codes listed per operation come from pkg/response, and tests
+// compare the document with the registered routes and the Go types behind
+// each schema, so it cannot silently fall behind the code.
+package openapi
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"payment-gateway-manjo/backend/pkg/snap"
+)
+
+// Object is one node of the document.
+type Object = map[string]interface{}
+
+//go:embed docs.html
+var DocsPage []byte
+
+var (
+	encodeOnce sync.Once
+	encoded    []byte
+)
+
+// JSON returns the encoded document, built on first use.
+func JSON() []byte {
+	encodeOnce.Do(func() {
+		var err error
+		if encoded, err = json.Marshal(Document()); err != nil {
+			panic(fmt.Sprintf("openapi: failed to encode document: %v", err))
+		}
+	})
+	return encoded
+}
+
+const signatureDescription = "Hex-encoded HMAC-SHA256 of the exact request body bytes, keyed with the " +
+	"secret shared with the gateway. A gateway started with SECURITY_LEGACY_SIGNATURES=true also accepts the " +
+	"HMAC of `merchantId|amount.value|partnerReferenceNo` for QR generation and of " +
+	"`originalReferenceNo|amount.value|transactionStatusDesc` for payment notifications. " +
+	"Those strings leave other fields unsigned; they are off by default and no longer accepted after 2027-03-31."
+
+func Document() Object {
+	return Object{
+		"openapi": "3.0.3",
+		"info": Object{
+			"title":   "Payment Gateway API",
+			"version": "1.0.0",
+			"description": "QRIS payment gateway following SNAP BI conventions. Every response carries a " +
+				"seven digit `responseCode`: the HTTP status, a two digit service code and a two digit case.",
+		},
+		"tags": []Object{
+			{"name": "QR", "description": "QR generation and acquirer payment notifications."},
+			{"name": "Transactions", "description": "Transaction queries."},
+			{"name": "Operations", "description": "Health, metrics and this document."},
+		},
+		"paths": Object{
+			"/api/v1/qr/generate": Object{
+				"post": signedOperation("QR", "generateQR", "Generate a QR code",
+					"Creates a PENDING transaction and returns the QR content to show the customer. "+
+						"partnerReferenceNo must be unique per transaction.",
+					"GenerateQRRequest",
+					snapResponses(snap.ServiceGenerateQR, "GenerateQRResponse",
+						snap.CaseBadRequest, snap.CaseInvalidFieldFormat, snap.CaseMissingMandatoryField,
+						snap.CaseUnauthorized,
+						snap.CaseDuplicatePartnerReference,
+						snap.CaseGeneralError, snap.CaseTimeout,
+					),
+				),
+			},
+			"/api/v1/qr/payment": Object{
+				"post": signedOperation("QR", "notifyPayment", "Notify a payment",
+					"Called by the acquirer once the customer has paid or the payment failed. Repeating "+
+						"the final status of a transaction is accepted and changes nothing.",
+					"PaymentNotificationRequest",
+					snapResponses(snap.ServiceNotify, "PaymentNotificationResponse",
+						snap.CaseBadRequest, snap.CaseInvalidFieldFormat, snap.CaseMissingMandatoryField,
+						snap.CaseUnauthorized,
+						snap.CaseTransactionExpired,
+						snap.CaseInvalidTransactionStatus, snap.CaseTransactionNotFound, snap.CaseInvalidAmount,
+						snap.CaseDuplicateExternalID,
+						snap.CaseGeneralError, snap.CaseTimeout,
+					),
+				),
+			},
+			"/api/v1/qr/{referenceNo}/events": Object{
+				"get": Object{
+					"tags":        []string{"QR"},
+					"operationId": "streamTransactionEvents",
+					"security":    []Object{{"signature": []string{}}},
+					"summary":     "Stream a transaction's status",
+					"description": "Server-Sent Events. The current status is sent first, then every change as a `status` " +
+						"event whose id is the history event id; the stream ends after a final status. Comment lines " +
+						"are sent as heartbeats. A client that falls behind, or that may have missed changes because the " +
+						"server's change stream was interrupted, is disconnected and gets a fresh snapshot " +
+						"when it reconnects. Sign `referenceNo|timestamp` with the merchant's feed key and pass it " +
+						"as X-Signature or, from EventSource, as the signature query parameter; a transaction of " +
+						"another merchant is answered as not found.",
+					"parameters": []Object{
+						{
+							"name": "referenceNo", "in": "path", "required": true,
+							"description": "Gateway reference returned by QR generation.",
+							"schema":      Object{"type": "string"},
+						},
+						{
+							"name": "merchantId", "in": "query", "required": true,
+							"description": "Merchant the transaction belongs to, whose feed key made the signature.",
+							"schema":      Object{"type": "string"},
+						},
+						{
+							"name": "timestamp", "in": "query", "required": true,
+							"description": "When the signature was made, formatted as YYYY-MM-DDTHH:mm:ss+07:00; " +
+								"must be within a few minutes of the server's clock.",
+							"schema": Object{"type": "string"},
+						},
+						queryParameter("signature", "Signature of `referenceNo|timestamp` with the merchant's feed key, when X-Signature cannot be sent."),
+						ref("parameters", "RequestID"),
+					},
+					"responses": eventStreamResponses(),
+				},
+			},
+			"/api/v1/transactions": Object{
+				"get": Object{
+					"tags":        []string{"Transactions"},
+					"operationId": "listTransactions",
+					"summary":     "List transactions",
+					"description": "Newest first. Filters combine; omitted filters match everything.",
+					"parameters": []Object{
+						queryParameter("merchantId", "Only transactions of this merchant."),
+						queryParameter("partnerReferenceNo", "Only the transaction with this partner reference."),
+						queryParameter("referenceNo", "Only the transaction with this gateway reference."),
+						queryParameter("status", "Only transactions in this status: PENDING, SUCCESS, FAILED or EXPIRED."),
+						ref("parameters", "RequestID"),
+					},
+					"responses": snapResponses(snap.ServiceQuery, "TransactionList",
+						snap.CaseGeneralError, snap.CaseTimeout,
+					),
+				},
+			},
+			"/api/v1/transactions/{referenceNo}/history": Object{
+				"get": Object{
+					"tags":        []string{"Transactions"},
+					"operationId": "getTransactionHistory",
+					"summary":     "Get a transaction's status history",
+					"description": "Every status change, oldest first, with what caused it.",
+					"parameters": []Object{
+						{
+							"name": "referenceNo", "in": "path", "required": true,
+							"description": "Gateway reference returned by QR generation.",
+							"schema":      Object{"type": "string"},
+						},
+						ref("parameters", "RequestID"),
+					},
+					"responses": snapResponses(snap.ServiceQuery, "TransactionHistory",
+						snap.CaseTransactionNotFound,
+						snap.CaseGeneralError, snap.CaseTimeout,
+					),
+				},
+			},
+			"/api/v1/merchants/{merchantId}/feed": Object{
+				"get": Object{
+					"tags":        []string{"Transactions"},
+					"operationId": "merchantFeed",
+					"security":    []Object{{"signature": []string{}}},
+					"summary":     "Live feed of a merchant's transactions (WebSocket)",
+					"description": "Upgrades to a WebSocket that sends every status change of the merchant's " +
+						"transactions as FeedMessage JSON text frames. Sign `merchantId|timestamp` with the merchant's " +
+						"feed key, which the gateway operator issues and which differs from the notification secret, " +
+						"and pass it as " +
+						"X-Signature or, from browsers, as the signature query parameter. After a disconnect, " +
+						"reconnect with lastEventId set to the last id received: the missed changes are sent first, " +
+						"followed by a `ready` message. Heartbeats and ping frames are sent on an interval; a client " +
+						"that falls behind, or that may have missed changes because the server's change stream was " +
+						"interrupted, is closed with code 1013 and should reconnect the same way.",
+					"parameters": []Object{
+						{
+							"name": "merchantId", "in": "path", "required": true,
+							"schema": Object{"type": "string"},
+						},
+						{
+							"name": "timestamp", "in": "query", "required": true,
+							"description": "When the signature was made, formatted as YYYY-MM-DDTHH:mm:ss+07:00; " +
+								"must be within a few minutes of the server's clock.",
+							"schema": Object{"type": "string"},
+						},
+						queryParameter("signature", "Signature of `merchantId|timestamp` with the merchant's feed key, when X-Signature cannot be sent."),
+						queryParameter("lastEventId", "Resume after this event id. The Last-Event-ID header works too."),
+						ref("parameters", "RequestID"),
+					},
+					"responses": feedResponses(),
+				},
+			},
+			"/health": Object{"get": livenessOperation("health", "Liveness (alias of /livez)")},
+			"/livez":  Object{"get": livenessOperation("livez", "Liveness")},
+			"/readyz": Object{
+				"get": Object{
+					"tags":        []string{"Operations"},
+					"operationId": "readyz",
+					"summary":     "Readiness",
+					"description": "Checks every dependency; fails while the server drains before shutdown.",
+					"responses": Object{
+						"200": jsonResponse("Ready to serve traffic.", "Readiness"),
+						"503": jsonResponse("A dependency is down or the server is shutting down.", "Readiness"),
+					},
+				},
+			},
+			"/metrics": Object{
+				"get": Object{
+					"tags":        []string{"Operations"},
+					"operationId": "metrics",
+					"summary":     "Prometheus metrics",
+					"description": "Served only while the metrics feature is enabled.",
+					"responses": Object{
+						"200": Object{
+							"description": "Metrics in the Prometheus text format.",
+							"content":     Object{"text/plain": Object{"schema": Object{"type": "string"}}},
+						},
+					},
+				},
+			},
+			"/openapi.json": Object{
+				"get": Object{
+					"tags":        []string{"Operations"},
+					"operationId": "openapi",
+					"summary":     "This document",
+					"responses": Object{
+						"200": Object{
+							"description": "The OpenAPI document.",
+							"content":     Object{"application/json": Object{"schema": Object{"type": "object"}}},
+						},
+					},
+				},
+			},
+			"/docs": Object{
+				"get": Object{
+					"tags":        []string{"Operations"},
+					"operationId": "docs",
+					"summary":     "Browsable documentation of this document",
+					"responses": Object{
+						"200": Object{
+							"description": "HTML page.",
+							"content":     Object{"text/html": Object{"schema": Object{"type": "string"}}},
+						},
+					},
+				},
+			},
+		},
+		"components": Object{
+			"securitySchemes": Object{
+				"signature": Object{
+					"type":        "apiKey",
+					"in":          "header",
+					"name":        "X-Signature",
+					"description": signatureDescription,
+				},
+			},
+			"parameters": Object{
+				"RequestID": Object{
+					"name": "X-Request-ID", "in": "header", "required": false,
+					"description": "Correlation ID echoed in the response and in error bodies; generated when absent.",
+					"schema":      Object{"type": "string"},
+				},
+			},
+			"schemas": schemas(),
+		},
+	}
+}
+
+func schemas() Object {
+	return Object{
+		"Amount": object(
+			[]string{"value", "currency"},
+			Object{
+				"value": Object{
+					"type": "string", "pattern": `^\d+\.\d{2}$`, "example": "15000.00",
+					"description": "Decimal amount with exactly two decimals.",
+				},
+				"currency": Object{
+					"type": "string", "example": "IDR",
+					"description": "ISO 4217 currency code.",
+				},
+			},
+		),
+		"GenerateQRRequest": object(
+			[]string{"merchantId", "partnerReferenceNo", "amount"},
+			Object{
+				"merchantId": Object{
+					"type": "string", "pattern": "^[A-Za-z0-9_-]{1,50}$", "example": "MERCHANT-1",
+				},
+				"partnerReferenceNo": Object{
+					"type": "string", "example": "INV-2024-0001",
+					"description": "The partner's own reference; unique per transaction.",
+				},
+				"amount": ref("schemas", "Amount"),
+			},
+		),
+		"GenerateQRResponse": object(
+			[]string{"responseCode", "responseMessage", "referenceNo", "partnerReferenceNo", "qrContent"},
+			Object{
+				"responseCode":       Object{"type": "string", "example": snap.Code(snap.ServiceGenerateQR, snap.CaseSuccessful)},
+				"responseMessage":    Object{"type": "string", "example": snap.CaseSuccessful.Message},
+				"referenceNo":        Object{"type": "string", "description": "Gateway reference of the new transaction."},
+				"partnerReferenceNo": Object{"type": "string"},
+				"qrContent":          Object{"type": "string", "description": "QRIS payload to render as a QR code."},
+			},
+		),
+		"PaymentNotificationRequest": object(
+			[]string{"originalReferenceNo", "originalPartnerReferenceNo", "transactionStatusDesc", "paidTime", "amount"},
+			Object{
+				"originalReferenceNo":        Object{"type": "string", "description": "referenceNo from QR generation."},
+				"originalPartnerReferenceNo": Object{"type": "string"},
+				"transactionStatusDesc": Object{
+					"type": "string", "enum": []string{"SUCCESS", "FAILED"}, "example": "SUCCESS",
+					"description": "SUCCESS when the customer paid, FAILED otherwise.",
+				},
+				"paidTime": Object{
+					"type": "string", "format": "date-time", "example": "2024-03-01T10:15:30+07:00",
+					"description": "When the acquirer took the payment, with a numeric UTC offset.",
+				},
+				"amount": ref("schemas", "Amount"),
+			},
+		),
+		"PaymentNotificationResponse": object(
+			[]string{"responseCode", "responseMessage", "transactionStatusDesc"},
+			Object{
+				"responseCode":          Object{"type": "string", "example": snap.Code(snap.ServiceNotify, snap.CaseSuccessful)},
+				"responseMessage":       Object{"type": "string", "example": snap.CaseSuccessful.Message},
+				"transactionStatusDesc": Object{"type": "string", "description": "The transaction's status after the notification."},
+			},
+		),
+		"ErrorResponse": object(
+			[]string{"responseCode", "responseMessage"},
+			Object{
+				"responseCode":    Object{"type": "string", "example": snap.Code(snap.ServiceGenerateQR, snap.CaseMissingMandatoryField)},
+				"responseMessage": Object{"type": "string", "example": snap.CaseMissingMandatoryField.Message},
+				"error":           Object{"type": "string", "description": "Human readable detail."},
+				"errors":          Object{"type": "array", "items": ref("schemas", "FieldError"), "description": "Set when fields failed validation."},
+				"requestId":       Object{"type": "string"},
+			},
+		),
+		"FieldError": object(
+			[]string{"field", "rule", "message"},
+			Object{
+				"field":   Object{"type": "string", "example": "amount.value"},
+				"rule":    Object{"type": "string", "example": "required"},
+				"message": Object{"type": "string"},
+			},
+		),
+		"Transaction": object(
+			[]string{"id", "merchant_id", "amount", "currency", "partner_reference_number", "reference_number", "status", "transaction_date", "created_at", "updated_at"},
+			Object{
+				"id":                       Object{"type": "integer"},
+				"merchant_id":              Object{"type": "string"},
+				"amount":                   Object{"type": "number", "example": 15000},
+				"currency":                 Object{"type": "string", "example": "IDR"},
+				"trx_id":                   Object{"type": "string"},
+				"partner_reference_number": Object{"type": "string"},
+				"reference_number":         Object{"type": "string"},
+				"status":                   Object{"type": "string", "enum": []string{"PENDING", "SUCCESS", "FAILED", "EXPIRED"}},
+				"transaction_date":         Object{"type": "string", "format": "date-time"},
+				"paid_date":                Object{"type": "string", "format": "date-time", "description": "paidTime reported by the acquirer."},
+				"received_at":              Object{"type": "string", "format": "date-time", "description": "When the gateway received the payment notification."},
+				"paid_time_flag": Object{
+					"type": "string", "enum": []string{"BEFORE_TRANSACTION_DATE", "IN_FUTURE"},
+					"description": "Set when the acquirer's paidTime looks wrong.",
+				},
+				"qr_content": Object{"type": "string"},
+				"created_at": Object{"type": "string", "format": "date-time"},
+				"updated_at": Object{"type": "string", "format": "date-time"},
+			},
+		),
+		"TransactionEvent": object(
+			[]string{"id", "transaction_id", "reference_number", "new_status", "source", "created_at"},
+			Object{
+				"id":               Object{"type": "integer"},
+				"transaction_id":   Object{"type": "integer"},
+				"reference_number": Object{"type": "string"},
+				"old_status":       Object{"type": "string", "description": "Empty for the event that created the transaction."},
+				"new_status":       Object{"type": "string"},
+				"source": Object{
+					"type": "string", "enum": []string{"QR_GENERATION", "ACQUIRER_NOTIFICATION", "EXPIRY_JOB", "ADMIN"},
+				},
+				"payload_hash": Object{"type": "string", "description": "SHA-256 of the notification body that caused the change."},
+				"actor":        Object{"type": "string"},
+				"reason":       Object{"type": "string", "description": "Why an operator changed the status."},
+				"created_at":   Object{"type": "string", "format": "date-time"},
+			},
+		),
+		"StatusChange": object(
+			[]string{"event_id", "reference_number", "partner_reference_number", "merchant_id", "new_status",
+				"amount", "currency", "source", "occurred_at"},
+			Object{
+				"event_id": Object{
+					"type": "integer", "description": "Id of the history event; only grows. 0 for transactions without history.",
+				},
+				"reference_number":         Object{"type": "string"},
+				"partner_reference_number": Object{"type": "string"},
+				"merchant_id":              Object{"type": "string"},
+				"old_status":               Object{"type": "string", "description": "Empty when the transaction was created."},
+				"new_status":               Object{"type": "string"},
+				"amount":                   Object{"type": "number"},
+				"currency":                 Object{"type": "string"},
+				"source":                   Object{"type": "string"},
+				"occurred_at":              Object{"type": "string", "format": "date-time"},
+			},
+		),
+		"FeedMessage": object(
+			[]string{"type"},
+			Object{
+				"type": Object{"type": "string", "enum": []string{"status", "ready", "heartbeat"}},
+				"id": Object{
+					"type":        "integer",
+					"description": "Event id of a status message; for ready, the last id sent so far.",
+				},
+				"data": ref("schemas", "StatusChange"),
+			},
+		),
+		"TransactionList":    envelope(snap.ServiceQuery, "Transaction"),
+		"TransactionHistory": envelope(snap.ServiceQuery, "TransactionEvent"),
+		"Liveness": object(
+			[]string{"status"},
+			Object{"status": Object{"type": "string", "example": "UP"}},
+		),
+		"Readiness": object(
+			[]string{"status", "components"},
+			Object{
+				"status": Object{"type": "string", "enum": []string{"UP", "DOWN"}},
+				"components": Object{
+					"type":                 "object",
+					"additionalProperties": ref("schemas", "ComponentStatus"),
+				},
+			},
+		),
+		"ComponentStatus": object(
+			[]string{"status"},
+			Object{
+				"status": Object{"type": "string", "enum": []string{"UP", "DOWN"}},
+				"error":  Object{"type": "string"},
+			},
+		),
+	}
+}
+
+func signedOperation(tag, operationID, summary, description, requestSchema string, responses Object) Object {
+	return Object{
+		"tags":        []string{tag},
+		"operationId": operationID,
+		"summary":     summary,
+		"description": description,
+		"security":    []Object{{"signature": []string{}}},
+		"parameters":  []Object{ref("parameters", "RequestID")},
+		"requestBody": Object{
+			"required": true,
+			"content":  Object{"application/json": Object{"schema": ref("schemas", requestSchema)}},
+		},
+		"responses": responses,
+	}
+}
+
+func livenessOperation(operationID, summary string) Object {
+	return Object{
+		"tags":        []string{"Operations"},
+		"operationId": operationID,
+		"summary":     summary,
+		"responses":   Object{"200": jsonResponse("The process is serving HTTP.", "Liveness")},
+	}
+}
+
+// snapResponses lists the success case and every given error case under its
+// HTTP status, naming the full response code for service.
+func snapResponses(service snap.ServiceCode, successSchema string, errorCases ...snap.Case) Object {
+	responses := Object{
+		"200": jsonResponse(fmt.Sprintf("`%s` %s", snap.Code(service, snap.CaseSuccessful), snap.CaseSuccessful.Message), successSchema),
+	}
+
+	byStatus := map[int][]string{}
+	for _, snapCase := range errorCases {
+		byStatus[snapCase.HTTPStatus] = append(byStatus[snapCase.HTTPStatus],
+			fmt.Sprintf("`%s` %s", snap.Code(service, snapCase), snapCase.Message))
+	}
+	statuses := make([]int, 0, len(byStatus))
+	for status := range byStatus {
+		statuses = append(statuses, status)
+	}
+	sort.Ints(statuses)
+	for _, status := range statuses {
+		description := http.StatusText(status) + ":\n\n- " + strings.Join(byStatus[status], "\n- ")
+		responses[fmt.Sprint(status)] = jsonResponse(description, "ErrorResponse")
+	}
+	return responses
+}
+
+// eventStreamResponses documents a status stream, whose events carry a
+// StatusChange as data.
+func eventStreamResponses() Object {
+	responses := snapResponses(snap.ServiceQuery, "StatusChange",
+		snap.CaseInvalidFieldFormat,
+		snap.CaseUnauthorized,
+		snap.CaseTransactionNotFound,
+		snap.CaseGeneralError, snap.CaseTimeout,
+	)
+	responses["200"] = Object{
+		"description": "A `text/event-stream` of `status` events; each data line is a StatusChange.",
+		"content": Object{"text/event-stream": Object{
+			"schema":  Object{"type": "string"},
+			"example": "id: 2\nevent: status\nretry: 3000\ndata: {\"event_id\":2,\"new_status\":\"SUCCESS\",...}\n\n",
+		}},
+	}
+	return responses
+}
+
+// feedResponses documents a WebSocket upgrade and the errors that prevent it.
+func feedResponses() Object {
+	responses := snapResponses(snap.ServiceQuery, "FeedMessage",
+		snap.CaseInvalidFieldFormat,
+		snap.CaseUnauthorized,
+	)
+	delete(responses, "200")
+	responses["101"] = Object{"description": "Switched to the WebSocket protocol; frames are FeedMessage JSON."}
+	return responses
+}
+
+func envelope(service snap.ServiceCode, itemSchema string) Object {
+	return object(
+		[]string{"responseCode", "responseMessage"},
+		Object{
+			"responseCode":    Object{"type": "string", "example": snap.Code(service, snap.CaseSuccessful)},
+			"responseMessage": Object{"type": "string", "example": snap.CaseSuccessful.Message},
+			"data":            Object{"type": "array", "items": ref("schemas", itemSchema)},
+		},
+	)
+}
+
+func jsonResponse(description, schema string) Object {
+	return Object{
+		"description": description,
+		"content":     Object{"application/json": Object{"schema": ref("schemas", schema)}},
+	}
+}
+
+func object(required []string, properties Object) Object {
+	return Object{"type": "object", "required": required, "properties": properties}
+}
+
+func queryParameter(name, description string) Object {
+	return Object{
+		"name": name, "in": "query", "required": false,
+		"description": description,
+		"schema":      Object{"type": "string"},
+	}
+}
+
+func ref(kind, name string) Object {
+	return Object{"$ref": "#/components/" + kind + "/" + name}
+}
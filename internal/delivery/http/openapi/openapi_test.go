@@ -0,0 +1,125 @@
+package openapi_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"payment-gateway-manjo/backend/internal/delivery/http/dto"
+	"payment-gateway-manjo/backend/internal/delivery/http/handler"
+	"payment-gateway-manjo/backend/internal/delivery/http/openapi"
+	"payment-gateway-manjo/backend/internal/domain/entity"
+	"payment-gateway-manjo/backend/internal/infrastructure/health"
+	"payment-gateway-manjo/backend/pkg/snap"
+)
+
+// TestSchemasMatchTypes compares each schema with the Go type the API
+// actually decodes or encodes, field by field.
+func TestSchemasMatchTypes(t *testing.T) {
+	schemas := openapi.Document()["components"].(openapi.Object)["schemas"].(openapi.Object)
+
+	tests := []struct {
+		schema string
+		value  interface{}
+		// request types declare required fields through binding tags.
+		request bool
+	}{
+		{"Amount", dto.Amount{}, true},
+		{"GenerateQRRequest", dto.GenerateQRRequest{}, true},
+		{"PaymentNotificationRequest", dto.PaymentNotificationRequest{}, true},
+		{"GenerateQRResponse", handler.GenerateQRResponse{}, false},
+		{"PaymentNotificationResponse", handler.PaymentNotificationResponse{}, false},
+		{"ErrorResponse", snap.ErrorResponse{}, false},
+		{"FieldError", snap.FieldError{}, false},
+		{"Transaction", entity.Transaction{}, false},
+		{"TransactionEvent", entity.TransactionEvent{}, false},
+		{"StatusChange", entity.StatusChange{}, false},
+		{"FeedMessage", handler.FeedMessage{}, false},
+		{"ComponentStatus", health.ComponentStatus{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.schema, func(t *testing.T) {
+			schema, ok := schemas[tt.schema].(openapi.Object)
+			if !ok {
+				t.Fatalf("schema %s is missing", tt.schema)
+			}
+
+			var properties []string
+			for name := range schema["properties"].(openapi.Object) {
+				properties = append(properties, name)
+			}
+			sort.Strings(properties)
+
+			fields, required := jsonFields(reflect.TypeOf(tt.value))
+			if !reflect.DeepEqual(properties, fields) {
+				t.Errorf("properties %v, Go fields %v", properties, fields)
+			}
+
+			if tt.request {
+				documented := append([]string(nil), schema["required"].([]string)...)
+				sort.Strings(documented)
+				if !reflect.DeepEqual(documented, required) {
+					t.Errorf("required %v, Go binding requires %v", documented, required)
+				}
+			}
+		})
+	}
+}
+
+func TestReferencesResolve(t *testing.T) {
+	document := openapi.Document()
+	components := document["components"].(openapi.Object)
+
+	var walk func(path string, node interface{})
+	walk = func(path string, node interface{}) {
+		switch n := node.(type) {
+		case openapi.Object:
+			if target, ok := n["$ref"].(string); ok {
+				parts := strings.Split(strings.TrimPrefix(target, "#/components/"), "/")
+				kind, _ := components[parts[0]].(openapi.Object)
+				if len(parts) != 2 || kind[parts[1]] == nil {
+					t.Errorf("%s: unresolved $ref %s", path, target)
+				}
+			}
+			for key, child := range n {
+				walk(path+"/"+key, child)
+			}
+		case []openapi.Object:
+			for _, child := range n {
+				walk(path, child)
+			}
+		}
+	}
+	walk("#", document)
+
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(openapi.JSON(), &decoded); err != nil {
+		t.Fatalf("JSON() is not valid JSON: %v", err)
+	}
+}
+
+// jsonFields returns the JSON names of t's fields and those binding requires;
+// nested structs count as required because their own fields are.
+func jsonFields(t reflect.Type) (fields, required []string) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "-" || !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		fields = append(fields, name)
+		binding := field.Tag.Get("binding")
+		if strings.Contains(binding, "required") || field.Type.Kind() == reflect.Struct && binding == "" && field.Type.PkgPath() != "time" {
+			required = append(required, name)
+		}
+	}
+	sort.Strings(fields)
+	sort.Strings(required)
+	return fields, required
+}
@@ -0,0 +1,196 @@
+package router
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"payment-gateway-manjo/backend/internal/delivery/http/dto"
+	"payment-gateway-manjo/backend/internal/delivery/http/handler"
+	"payment-gateway-manjo/backend/internal/domain/entity"
+	"payment-gateway-manjo/backend/pkg/crypto"
+	"payment-gateway-manjo/backend/pkg/snaptime"
+
+	"github.com/gorilla/websocket"
+)
+
+func feedQuery(merchantID string, signedAt time.Time, lastEventID uint) string {
+	return feedQueryWithKey(merchantID, signedAt, lastEventID, crypto.DeriveMerchantKey(merchantID, testFeedSecret))
+}
+
+func feedQueryWithKey(merchantID string, signedAt time.Time, lastEventID uint, key string) string {
+	timestamp := snaptime.Format(signedAt)
+	query := url.Values{
+		"timestamp": {timestamp},
+		"signature": {crypto.GenerateSignature(crypto.GenerateFeedSignatureString(merchantID, timestamp), key)},
+	}
+	if lastEventID > 0 {
+		query.Set("lastEventId", fmt.Sprint(lastEventID))
+	}
+	return "/api/v1/merchants/" + merchantID + "/feed?" + query.Encode()
+}
+
+// feedClient reads in the background, as a real client must for pings to be
+// answered, and hands messages over one at a time.
+type feedClient struct {
+	t        *testing.T
+	conn     *websocket.Conn
+	messages chan handler.FeedMessage
+}
+
+func dialFeed(t *testing.T, server *httptest.Server, merchantID string, lastEventID uint) *feedClient {
+	t.Helper()
+	target := "ws" + strings.TrimPrefix(server.URL, "http") + feedQuery(merchantID, time.Now(), lastEventID)
+	conn, _, err := websocket.DefaultDialer.Dial(target, nil)
+	if err != nil {
+		t.Fatalf("failed to open feed: %v", err)
+	}
+	client := &feedClient{t: t, conn: conn, messages: make(chan handler.FeedMessage, 64)}
+	go func() {
+		defer close(client.messages)
+		for {
+			var message handler.FeedMessage
+			if err := conn.ReadJSON(&message); err != nil {
+				return
+			}
+			client.messages <- message
+		}
+	}()
+	t.Cleanup(func() { conn.Close() })
+	return client
+}
+
+// next returns the next message that is not a heartbeat.
+func (f *feedClient) next() handler.FeedMessage {
+	f.t.Helper()
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case message, ok := <-f.messages:
+			if !ok {
+				f.t.Fatal("feed closed unexpectedly")
+			}
+			if message.Type == handler.FeedHeartbeat {
+				continue
+			}
+			return message
+		case <-timeout:
+			f.t.Fatal("timed out waiting for a feed message")
+		}
+	}
+}
+
+func (f *feedClient) awaitHeartbeat() {
+	f.t.Helper()
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case message, ok := <-f.messages:
+			if !ok {
+				f.t.Fatal("feed closed unexpectedly")
+			}
+			if message.Type == handler.FeedHeartbeat {
+				return
+			}
+			f.t.Fatalf("got %+v while idle, want a heartbeat", message)
+		case <-timeout:
+			f.t.Fatal("no heartbeat was sent while the feed was idle")
+		}
+	}
+}
+
+func (f *feedClient) nextStatus(referenceNo, status string) handler.FeedMessage {
+	f.t.Helper()
+	message := f.next()
+	if message.Type != handler.FeedStatus || message.Data == nil ||
+		message.Data.ReferenceNumber != referenceNo || message.Data.NewStatus != status || message.ID != message.Data.EventID {
+		f.t.Fatalf("got %+v (%+v), want %s of %s", message, message.Data, status, referenceNo)
+	}
+	return message
+}
+
+func (h *harness) generate(merchantID, partnerReferenceNo string) string {
+	h.t.Helper()
+	qr := dto.GenerateQRRequest{
+		MerchantID:         merchantID,
+		PartnerReferenceNo: partnerReferenceNo,
+		Amount:             dto.Amount{Value: "15000.00", Currency: "IDR"},
+	}
+	generated := expect(h.t, h.do(http.MethodPost, "/api/v1/qr/generate", qr, signQR(qr)), http.StatusOK, "2004700")
+	return generated["referenceNo"].(string)
+}
+
+func TestMerchantFeed(t *testing.T) {
+	h := newHarness(t)
+	server := httptest.NewServer(h.engine)
+	t.Cleanup(server.Close)
+
+	feed := dialFeed(t, server, "MERCHANT-1", 0)
+	if ready := feed.next(); ready.Type != handler.FeedReady || ready.ID != 0 {
+		t.Fatalf("first message = %+v, want ready without replay", ready)
+	}
+
+	first := h.generate("MERCHANT-1", "PARTNER-1")
+	h.generate("MERCHANT-2", "PARTNER-2")
+	feed.nextStatus(first, entity.StatusPending)
+
+	payment := dto.PaymentNotificationRequest{
+		OriginalReferenceNo:        first,
+		OriginalPartnerReferenceNo: "PARTNER-1",
+		TransactionStatusDesc:      "SUCCESS",
+		PaidTime:                   snaptime.Format(time.Now().Add(time.Second)),
+		Amount:                     dto.Amount{Value: "15000.00", Currency: "IDR"},
+	}
+	expect(t, h.do(http.MethodPost, "/api/v1/qr/payment", payment, signPayment(payment)), http.StatusOK, "2005100")
+	paid := feed.nextStatus(first, entity.StatusSuccess)
+
+	// MERCHANT-2's transaction came in between; this being next proves it
+	// was filtered out.
+	second := h.generate("MERCHANT-1", "PARTNER-3")
+	feed.nextStatus(second, entity.StatusPending)
+
+	feed.awaitHeartbeat()
+
+	// Changes made while disconnected are replayed on reconnect.
+	feed.conn.Close()
+	third := h.generate("MERCHANT-1", "PARTNER-4")
+
+	resumed := dialFeed(t, server, "MERCHANT-1", paid.ID)
+	resumed.nextStatus(second, entity.StatusPending)
+	missed := resumed.nextStatus(third, entity.StatusPending)
+	if ready := resumed.next(); ready.Type != handler.FeedReady || ready.ID != missed.ID {
+		t.Fatalf("after replay got %+v, want ready with id %d", ready, missed.ID)
+	}
+
+	fourth := h.generate("MERCHANT-1", "PARTNER-5")
+	resumed.nextStatus(fourth, entity.StatusPending)
+}
+
+func TestMerchantFeedRejections(t *testing.T) {
+	h := newHarness(t)
+	valid := feedQuery("MERCHANT-1", time.Now(), 0)
+
+	tests := []struct {
+		name         string
+		path         string
+		status       int
+		responseCode string
+	}{
+		{"missing signature", "/api/v1/merchants/MERCHANT-1/feed", http.StatusUnauthorized, "4014800"},
+		{"other merchant's signature", strings.Replace(valid, "MERCHANT-1", "MERCHANT-2", 1), http.StatusUnauthorized, "4014800"},
+		{"signed with SECRET_KEY", feedQueryWithKey("MERCHANT-1", time.Now(), 0, testSecret), http.StatusUnauthorized, "4014800"},
+		{"signed with the feed secret", feedQueryWithKey("MERCHANT-1", time.Now(), 0, testFeedSecret), http.StatusUnauthorized, "4014800"},
+		{"stale timestamp", feedQuery("MERCHANT-1", time.Now().Add(-time.Hour), 0), http.StatusUnauthorized, "4014800"},
+		{"malformed timestamp", "/api/v1/merchants/MERCHANT-1/feed?timestamp=yesterday&signature=x", http.StatusBadRequest, "4004801"},
+		{"malformed lastEventId", valid + "&lastEventId=latest", http.StatusBadRequest, "4004801"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expect(t, h.do(http.MethodGet, tt.path, nil, ""), tt.status, tt.responseCode)
+		})
+	}
+}
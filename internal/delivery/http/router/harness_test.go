@@ -0,0 +1,145 @@
+package router
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"payment-gateway-manjo/backend/internal/delivery/http/dto"
+	"payment-gateway-manjo/backend/internal/infrastructure/broker"
+	"payment-gateway-manjo/backend/internal/infrastructure/config"
+	"payment-gateway-manjo/backend/internal/infrastructure/health"
+	"payment-gateway-manjo/backend/internal/infrastructure/memory"
+	"payment-gateway-manjo/backend/internal/usecase"
+	"payment-gateway-manjo/backend/pkg/crypto"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	testSecret     = "e2e-secret"
+	testFeedSecret = "e2e-feed-secret"
+)
+
+// harness serves the real router on top of the in-memory repositories.
+type harness struct {
+	t      *testing.T
+	engine *gin.Engine
+	store  *memory.Store
+}
+
+func newHarness(t *testing.T) *harness {
+	return newHarnessWith(t, func(*config.Config) {})
+}
+
+func newHarnessWith(t *testing.T, configure func(cfg *config.Config)) *harness {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	cfg := &config.Config{
+		Security: config.SecurityConfig{
+			SecretKey:           testSecret,
+			FeedSecret:          testFeedSecret,
+			MaxBodyBytes:        1 << 20,
+			FeedSignatureMaxAge: 5 * time.Minute,
+		},
+		Tracing:  config.TracingConfig{ServiceName: "payment-gateway-test"},
+		Payment:  config.PaymentConfig{PaidTimeMaxSkew: 5 * time.Minute},
+		Realtime: config.RealtimeConfig{Heartbeat: 50 * time.Millisecond, BufferSize: 16},
+		Features: config.FeatureConfig{Metrics: true},
+	}
+	configure(cfg)
+
+	store := memory.NewStore()
+	statusChanges := broker.NewMemoryBroker(cfg.Realtime.BufferSize)
+	transactor := memory.NewTransactor(store, statusChanges)
+	engine, err := New(cfg, Dependencies{
+		QRUsecase: usecase.NewQRGeneratorUsecase(transactor, usecase.Timeouts{}),
+		PaymentUsecase: usecase.NewPaymentUsecase(
+			memory.NewTransactionRepository(store),
+			memory.NewTransactionEventRepository(store),
+			transactor,
+			usecase.Timeouts{},
+			cfg.Payment.PaidTimeMaxSkew,
+		),
+		StatusChanges: statusChanges,
+		Probe:         health.NewProbe(time.Second),
+		Logger:        slog.New(slog.NewTextHandler(io.Discard, nil)),
+	})
+	if err != nil {
+		t.Fatalf("failed to build router: %v", err)
+	}
+	return &harness{t: t, engine: engine, store: store}
+}
+
+// do sends body as-is; pass a string for raw or deliberately broken JSON and
+// any other value to have it encoded.
+func (h *harness) do(method, path string, body interface{}, signature string) *httptest.ResponseRecorder {
+	h.t.Helper()
+
+	var reader io.Reader
+	switch b := body.(type) {
+	case nil:
+	case string:
+		reader = bytes.NewBufferString(b)
+	default:
+		encoded, err := json.Marshal(b)
+		if err != nil {
+			h.t.Fatalf("failed to encode request: %v", err)
+		}
+		reader = bytes.NewReader(encoded)
+	}
+
+	req := httptest.NewRequest(method, path, reader)
+	req.Header.Set("Content-Type", "application/json")
+	if signature != "" {
+		req.Header.Set("X-Signature", signature)
+	}
+	rec := httptest.NewRecorder()
+	h.engine.ServeHTTP(rec, req)
+	return rec
+}
+
+// signQR and signPayment sign body encoded the way do sends it.
+func signQR(body dto.GenerateQRRequest) string {
+	return signEncoded(body)
+}
+
+func signPayment(body dto.PaymentNotificationRequest) string {
+	return signEncoded(body)
+}
+
+func signEncoded(body interface{}) string {
+	encoded, err := json.Marshal(body)
+	if err != nil {
+		panic(err)
+	}
+	return signBody(string(encoded))
+}
+
+// signBody signs the exact bytes that will be sent.
+func signBody(body string) string {
+	return crypto.GenerateSignature(body, testSecret)
+}
+
+func decode(t *testing.T, rec *httptest.ResponseRecorder) map[string]interface{} {
+	t.Helper()
+	var body map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("response is not JSON: %v: %s", err, rec.Body.String())
+	}
+	return body
+}
+
+func expect(t *testing.T, rec *httptest.ResponseRecorder, status int, responseCode string) map[string]interface{} {
+	t.Helper()
+	body := decode(t, rec)
+	if rec.Code != status || body["responseCode"] != responseCode {
+		t.Fatalf("got %d %v, want %d %s: %s", rec.Code, body["responseCode"], status, responseCode, rec.Body.String())
+	}
+	return body
+}
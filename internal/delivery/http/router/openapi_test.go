@@ -0,0 +1,66 @@
+package router
+
+import (
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+
+	"payment-gateway-manjo/backend/internal/delivery/http/openapi"
+)
+
+var pathParam = regexp.MustCompile(`:(\w+)`)
+
+// TestRoutesMatchSpec fails when a route is added without documenting it or
+// the document names an operation the router does not serve.
+func TestRoutesMatchSpec(t *testing.T) {
+	h := newHarness(t)
+
+	routes := map[string]bool{}
+	for _, route := range h.engine.Routes() {
+		routes[route.Method+" "+pathParam.ReplaceAllString(route.Path, "{$1}")] = true
+	}
+
+	documented := map[string]bool{}
+	for path, item := range openapi.Document()["paths"].(openapi.Object) {
+		for method := range item.(openapi.Object) {
+			documented[strings.ToUpper(method)+" "+path] = true
+		}
+	}
+
+	for _, missing := range difference(routes, documented) {
+		t.Errorf("route %s is not in the OpenAPI document", missing)
+	}
+	for _, extra := range difference(documented, routes) {
+		t.Errorf("OpenAPI document describes %s, which is not routed", extra)
+	}
+}
+
+func TestDocsAreServed(t *testing.T) {
+	h := newHarness(t)
+
+	spec := h.do(http.MethodGet, "/openapi.json", nil, "")
+	if spec.Code != http.StatusOK || !strings.HasPrefix(spec.Header().Get("Content-Type"), "application/json") {
+		t.Fatalf("GET /openapi.json = %d %s", spec.Code, spec.Header().Get("Content-Type"))
+	}
+	if !strings.Contains(spec.Body.String(), `"openapi":"3.0.3"`) {
+		t.Errorf("GET /openapi.json body is not the document: %.100s", spec.Body.String())
+	}
+
+	docs := h.do(http.MethodGet, "/docs", nil, "")
+	if docs.Code != http.StatusOK || !strings.Contains(docs.Body.String(), "openapi.json") {
+		t.Errorf("GET /docs = %d, want the docs page", docs.Code)
+	}
+}
+
+func difference(a, b map[string]bool) []string {
+	var out []string
+	for key := range a {
+		if !b[key] {
+			out = append(out, key)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
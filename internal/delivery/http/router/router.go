@@ -0,0 +1,92 @@
+package router
+
+import (
+	"fmt"
+	"log/slog"
+
+	"payment-gateway-manjo/backend/internal/delivery/http/handler"
+	"payment-gateway-manjo/backend/internal/delivery/http/middleware"
+	"payment-gateway-manjo/backend/internal/delivery/http/validation"
+	"payment-gateway-manjo/backend/internal/domain/pubsub"
+	"payment-gateway-manjo/backend/internal/infrastructure/config"
+	"payment-gateway-manjo/backend/internal/infrastructure/health"
+	"payment-gateway-manjo/backend/internal/infrastructure/metrics"
+	"payment-gateway-manjo/backend/internal/usecase"
+
+	"github.com/gin-contrib/cors"
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+)
+
+// Dependencies are the application services the HTTP layer is built on.
+type Dependencies struct {
+	QRUsecase      usecase.QRGeneratorUsecase
+	PaymentUsecase usecase.PaymentUsecase
+	StatusChanges  pubsub.Subscriber
+	Probe          *health.Probe
+	Logger         *slog.Logger
+}
+
+// New builds the gin engine with every middleware and route the API serves.
+// The server binary and the end-to-end tests share it, so both exercise the
+// same wiring.
+func New(cfg *config.Config, deps Dependencies) (*gin.Engine, error) {
+	validation.Register()
+
+	qrHandler := handler.NewQRHandler(deps.QRUsecase)
+	paymentHandler := handler.NewPaymentHandler(deps.PaymentUsecase)
+	streamHandler := handler.NewStreamHandler(deps.PaymentUsecase, deps.StatusChanges, cfg.Realtime.Heartbeat)
+	feedHandler := handler.NewFeedHandler(deps.PaymentUsecase, deps.StatusChanges, cfg.Realtime.Heartbeat, cfg.CORS.AllowOrigins)
+	healthHandler := handler.NewHealthHandler(deps.Probe)
+	docsHandler := handler.NewDocsHandler()
+	signatureValidator := middleware.NewSignatureValidator(cfg.Security)
+
+	router := gin.New()
+	if err := router.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		return nil, fmt.Errorf("failed to set trusted proxies: %w", err)
+	}
+	router.Use(middleware.RequestID())
+	router.Use(otelgin.Middleware(cfg.Tracing.ServiceName))
+	router.Use(middleware.AccessLog(deps.Logger))
+	router.Use(middleware.Metrics())
+	router.Use(middleware.Recovery(deps.Logger))
+
+	if len(cfg.CORS.AllowOrigins) > 0 {
+		router.Use(cors.New(cors.Config{
+			AllowOrigins:     cfg.CORS.AllowOrigins,
+			AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
+			AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "X-Signature", "X-Request-ID"},
+			ExposeHeaders:    []string{"Content-Length", "X-Request-ID"},
+			AllowCredentials: cfg.CORS.AllowCredentials,
+		}))
+	}
+
+	router.GET("/health", healthHandler.Livez)
+	router.GET("/livez", healthHandler.Livez)
+	router.GET("/readyz", healthHandler.Readyz)
+	if cfg.Features.Metrics {
+		router.GET("/metrics", gin.WrapH(metrics.Handler()))
+	}
+	router.GET("/openapi.json", docsHandler.Spec)
+	router.GET("/docs", docsHandler.UI)
+
+	v1 := router.Group("/api/v1")
+	{
+		qr := v1.Group("/qr")
+		{
+			qr.POST("/generate", signatureValidator.ValidateQRSignature(), qrHandler.GenerateQR)
+			qr.POST("/payment", signatureValidator.ValidatePaymentSignature(), paymentHandler.ProcessPayment)
+			qr.GET("/:referenceNo/events", signatureValidator.ValidateStreamSignature(), streamHandler.TransactionEvents)
+		}
+
+		transactions := v1.Group("/transactions")
+		{
+			transactions.GET("", paymentHandler.GetTransactions)
+			transactions.GET("/:referenceNo/history", paymentHandler.GetTransactionHistory)
+		}
+
+		v1.GET("/merchants/:merchantId/feed", signatureValidator.ValidateFeedSignature(), feedHandler.MerchantFeed)
+	}
+
+	return router, nil
+}
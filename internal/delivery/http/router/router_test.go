@@ -0,0 +1,206 @@
+package router
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"payment-gateway-manjo/backend/internal/delivery/http/dto"
+	"payment-gateway-manjo/backend/internal/infrastructure/config"
+	"payment-gateway-manjo/backend/pkg/crypto"
+	"payment-gateway-manjo/backend/pkg/snaptime"
+)
+
+func TestGenerateNotifyList(t *testing.T) {
+	h := newHarness(t)
+
+	qr := dto.GenerateQRRequest{
+		MerchantID:         "MERCHANT-1",
+		PartnerReferenceNo: "PARTNER-1",
+		Amount:             dto.Amount{Value: "15000.00", Currency: "IDR"},
+	}
+	generated := expect(t, h.do(http.MethodPost, "/api/v1/qr/generate", qr, signQR(qr)), http.StatusOK, "2004700")
+	referenceNo, _ := generated["referenceNo"].(string)
+	if referenceNo == "" || generated["qrContent"] == "" {
+		t.Fatalf("generate response lacks referenceNo or qrContent: %v", generated)
+	}
+
+	payment := dto.PaymentNotificationRequest{
+		OriginalReferenceNo:        referenceNo,
+		OriginalPartnerReferenceNo: qr.PartnerReferenceNo,
+		TransactionStatusDesc:      "SUCCESS",
+		PaidTime:                   snaptime.Format(time.Now()),
+		Amount:                     qr.Amount,
+	}
+	paid := expect(t, h.do(http.MethodPost, "/api/v1/qr/payment", payment, signPayment(payment)), http.StatusOK, "2005100")
+	if paid["transactionStatusDesc"] != "SUCCESS" {
+		t.Errorf("transactionStatusDesc = %v, want SUCCESS", paid["transactionStatusDesc"])
+	}
+
+	// Acquirers retry notifications; a repeat must not fail.
+	expect(t, h.do(http.MethodPost, "/api/v1/qr/payment", payment, signPayment(payment)), http.StatusOK, "2005100")
+
+	listed := expect(t, h.do(http.MethodGet, "/api/v1/transactions?merchantId=MERCHANT-1", nil, ""), http.StatusOK, "2004800")
+	transactions, _ := listed["data"].([]interface{})
+	if len(transactions) != 1 {
+		t.Fatalf("listed %d transactions, want 1", len(transactions))
+	}
+	if transaction := transactions[0].(map[string]interface{}); transaction["status"] != "SUCCESS" || transaction["reference_number"] != referenceNo {
+		t.Errorf("listed transaction = %v", transaction)
+	}
+
+	history := expect(t, h.do(http.MethodGet, "/api/v1/transactions/"+referenceNo+"/history", nil, ""), http.StatusOK, "2004800")
+	if events, _ := history["data"].([]interface{}); len(events) != 2 {
+		t.Errorf("history has %d events, want creation and payment", len(events))
+	}
+}
+
+func TestGenerateQRRejections(t *testing.T) {
+	valid := dto.GenerateQRRequest{
+		MerchantID:         "MERCHANT-1",
+		PartnerReferenceNo: "PARTNER-1",
+		Amount:             dto.Amount{Value: "15000.00", Currency: "IDR"},
+	}
+	tampered := valid
+	tampered.Amount.Value = "1.00"
+	missingMerchant := valid
+	missingMerchant.MerchantID = ""
+	badCurrency := valid
+	badCurrency.Amount.Currency = "RUPIAH"
+
+	tests := []struct {
+		name      string
+		body      interface{}
+		signature string
+		status    int
+		code      string
+	}{
+		{"missing signature", valid, "", http.StatusUnauthorized, "4014700"},
+		{"signature from another key", valid, "deadbeef", http.StatusUnauthorized, "4014700"},
+		{"signature over different fields", tampered, signQR(valid), http.StatusUnauthorized, "4014700"},
+		{"malformed JSON", `{"merchantId": "MERCHANT-1",`, signQR(valid), http.StatusUnauthorized, "4014700"},
+		{"signed malformed JSON", `{"merchantId": "MERCHANT-1",`, signBody(`{"merchantId": "MERCHANT-1",`), http.StatusBadRequest, "4004700"},
+		{"wrong JSON type", `{"merchantId": 42}`, signBody(`{"merchantId": 42}`), http.StatusBadRequest, "4004700"},
+		{"missing mandatory field", missingMerchant, signQR(missingMerchant), http.StatusBadRequest, "4004702"},
+		{"invalid field format", badCurrency, signQR(badCurrency), http.StatusBadRequest, "4004701"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newHarness(t)
+			expect(t, h.do(http.MethodPost, "/api/v1/qr/generate", tt.body, tt.signature), tt.status, tt.code)
+
+			listed := expect(t, h.do(http.MethodGet, "/api/v1/transactions", nil, ""), http.StatusOK, "2004800")
+			if transactions, _ := listed["data"].([]interface{}); len(transactions) != 0 {
+				t.Errorf("a rejected request created %d transactions", len(transactions))
+			}
+		})
+	}
+}
+
+func TestPaymentRejections(t *testing.T) {
+	h := newHarness(t)
+	qr := dto.GenerateQRRequest{
+		MerchantID:         "MERCHANT-1",
+		PartnerReferenceNo: "PARTNER-1",
+		Amount:             dto.Amount{Value: "15000.00", Currency: "IDR"},
+	}
+	generated := expect(t, h.do(http.MethodPost, "/api/v1/qr/generate", qr, signQR(qr)), http.StatusOK, "2004700")
+	referenceNo := generated["referenceNo"].(string)
+
+	valid := dto.PaymentNotificationRequest{
+		OriginalReferenceNo:        referenceNo,
+		OriginalPartnerReferenceNo: qr.PartnerReferenceNo,
+		TransactionStatusDesc:      "SUCCESS",
+		PaidTime:                   snaptime.Format(time.Now()),
+		Amount:                     qr.Amount,
+	}
+	mismatch := valid
+	mismatch.Amount.Value = "14000.00"
+	unknown := valid
+	unknown.OriginalReferenceNo = "A000000000"
+	badTime := valid
+	badTime.PaidTime = "yesterday"
+	badStatus := valid
+	badStatus.TransactionStatusDesc = "PAID"
+
+	tests := []struct {
+		name      string
+		body      interface{}
+		signature string
+		status    int
+		code      string
+	}{
+		{"missing signature", valid, "", http.StatusUnauthorized, "4015100"},
+		{"invalid signature", valid, signPayment(mismatch), http.StatusUnauthorized, "4015100"},
+		{"malformed JSON", `not json`, signPayment(valid), http.StatusUnauthorized, "4015100"},
+		{"invalid paid time", badTime, signPayment(badTime), http.StatusBadRequest, "4005101"},
+		{"unknown status", badStatus, signPayment(badStatus), http.StatusBadRequest, "4005101"},
+		{"amount mismatch", mismatch, signPayment(mismatch), http.StatusNotFound, "4045113"},
+		{"unknown transaction", unknown, signPayment(unknown), http.StatusNotFound, "4045101"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expect(t, h.do(http.MethodPost, "/api/v1/qr/payment", tt.body, tt.signature), tt.status, tt.code)
+		})
+	}
+
+	listed := expect(t, h.do(http.MethodGet, "/api/v1/transactions?referenceNo="+referenceNo, nil, ""), http.StatusOK, "2004800")
+	if transaction := listed["data"].([]interface{})[0].(map[string]interface{}); transaction["status"] != "PENDING" {
+		t.Errorf("status after rejected notifications = %v, want PENDING", transaction["status"])
+	}
+}
+
+func TestRequestIDIsEchoed(t *testing.T) {
+	h := newHarness(t)
+	rec := h.do(http.MethodPost, "/api/v1/qr/generate", dto.GenerateQRRequest{}, "")
+	body := decode(t, rec)
+	if id := rec.Header().Get("X-Request-ID"); id == "" || body["requestId"] != id {
+		t.Errorf("X-Request-ID %q and body requestId %v should match", id, body["requestId"])
+	}
+}
+
+func TestBodySignatures(t *testing.T) {
+	const body = `{"merchantId":"MERCHANT-1","partnerReferenceNo":"PARTNER-1","amount":{"value":"15000.00","currency":"IDR"}}`
+	legacy := signBody((&dto.GenerateQRRequest{
+		MerchantID:         "MERCHANT-1",
+		PartnerReferenceNo: "PARTNER-1",
+		Amount:             dto.Amount{Value: "15000.00", Currency: "IDR"},
+	}).LegacySignatureString())
+	enableLegacy := func(cfg *config.Config) { cfg.Security.LegacySignatures = true }
+	smallBodies := func(cfg *config.Config) { cfg.Security.MaxBodyBytes = 64 }
+	rotating := func(cfg *config.Config) { cfg.Security.SecretKeyNext = "e2e-next-secret" }
+
+	tests := []struct {
+		name      string
+		configure func(cfg *config.Config)
+		body      string
+		signature string
+		status    int
+		code      string
+	}{
+		{"body signature", nil, body, signBody(body), http.StatusOK, "2004700"},
+		{"body signature with legacy enabled", enableLegacy, body, signBody(body), http.StatusOK, "2004700"},
+		{"legacy signature", nil, body, legacy, http.StatusUnauthorized, "4014700"},
+		{"legacy signature with legacy enabled", enableLegacy, body, legacy, http.StatusOK, "2004700"},
+		{"malformed JSON with legacy enabled", enableLegacy, `{"merchantId":`, legacy, http.StatusUnauthorized, "4014700"},
+		{"body changed after signing", nil, strings.Replace(body, "15000.00", "1.00", 1), signBody(body), http.StatusUnauthorized, "4014700"},
+		{"next key during a rotation", rotating, body, crypto.GenerateSignature(body, "e2e-next-secret"), http.StatusOK, "2004700"},
+		{"current key during a rotation", rotating, body, signBody(body), http.StatusOK, "2004700"},
+		{"next key without a rotation", nil, body, crypto.GenerateSignature(body, "e2e-next-secret"), http.StatusUnauthorized, "4014700"},
+		{"body over the size limit", smallBodies, body, signBody(body), http.StatusBadRequest, "4004700"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configure := tt.configure
+			if configure == nil {
+				configure = func(*config.Config) {}
+			}
+			h := newHarnessWith(t, configure)
+			expect(t, h.do(http.MethodPost, "/api/v1/qr/generate", tt.body, tt.signature), tt.status, tt.code)
+		})
+	}
+}
@@ -0,0 +1,170 @@
+package router
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"payment-gateway-manjo/backend/internal/delivery/http/dto"
+	"payment-gateway-manjo/backend/internal/domain/entity"
+	"payment-gateway-manjo/backend/pkg/crypto"
+	"payment-gateway-manjo/backend/pkg/snaptime"
+)
+
+// streamPath signs a status stream request the way merchantID would.
+func streamPath(referenceNo, merchantID string, signedAt time.Time) string {
+	timestamp := snaptime.Format(signedAt)
+	key := crypto.DeriveMerchantKey(merchantID, testFeedSecret)
+	query := url.Values{
+		"merchantId": {merchantID},
+		"timestamp":  {timestamp},
+		"signature":  {crypto.GenerateSignature(crypto.GenerateStreamSignatureString(referenceNo, timestamp), key)},
+	}
+	return "/api/v1/qr/" + referenceNo + "/events?" + query.Encode()
+}
+
+type sseEvent struct {
+	id, event string
+	change    entity.StatusChange
+}
+
+// sseReader reads events off a stream, counting the heartbeat comments seen
+// on the way.
+type sseReader struct {
+	t          *testing.T
+	scanner    *bufio.Scanner
+	heartbeats int
+}
+
+// next returns the next event, or false once the server ends the stream.
+func (r *sseReader) next() (sseEvent, bool) {
+	r.t.Helper()
+	var event sseEvent
+	seen := false
+	for r.scanner.Scan() {
+		line := r.scanner.Text()
+		switch {
+		case line == "":
+			if seen {
+				return event, true
+			}
+		case strings.HasPrefix(line, ":"):
+			r.heartbeats++
+		case strings.HasPrefix(line, "id:"):
+			event.id, seen = strings.TrimSpace(strings.TrimPrefix(line, "id:")), true
+		case strings.HasPrefix(line, "event:"):
+			event.event, seen = strings.TrimSpace(strings.TrimPrefix(line, "event:")), true
+		case strings.HasPrefix(line, "data:"):
+			if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data:")), &event.change); err != nil {
+				r.t.Fatalf("event data is not a status change: %v: %s", err, line)
+			}
+			seen = true
+		}
+	}
+	return event, false
+}
+
+func TestTransactionEventStream(t *testing.T) {
+	h := newHarness(t)
+	server := httptest.NewServer(h.engine)
+	t.Cleanup(server.Close)
+
+	qr := dto.GenerateQRRequest{
+		MerchantID:         "MERCHANT-1",
+		PartnerReferenceNo: "PARTNER-1",
+		Amount:             dto.Amount{Value: "15000.00", Currency: "IDR"},
+	}
+	generated := expect(t, h.do(http.MethodPost, "/api/v1/qr/generate", qr, signQR(qr)), http.StatusOK, "2004700")
+	referenceNo := generated["referenceNo"].(string)
+
+	client := &http.Client{Timeout: 5 * time.Second}
+	resp, err := client.Get(server.URL + streamPath(referenceNo, "MERCHANT-1", time.Now()))
+	if err != nil {
+		t.Fatalf("failed to open stream: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream") {
+		t.Fatalf("got %d %q, want 200 text/event-stream", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+	stream := &sseReader{t: t, scanner: bufio.NewScanner(resp.Body)}
+
+	snapshot, ok := stream.next()
+	if !ok || snapshot.event != "status" || snapshot.change.NewStatus != entity.StatusPending || snapshot.change.ReferenceNumber != referenceNo {
+		t.Fatalf("first event = %+v, want the PENDING snapshot", snapshot)
+	}
+
+	// Let a heartbeat through so the stream is known to stay open while idle.
+	time.Sleep(120 * time.Millisecond)
+
+	payment := dto.PaymentNotificationRequest{
+		OriginalReferenceNo:        referenceNo,
+		OriginalPartnerReferenceNo: qr.PartnerReferenceNo,
+		TransactionStatusDesc:      "SUCCESS",
+		PaidTime:                   snaptime.Format(time.Now()),
+		Amount:                     qr.Amount,
+	}
+	expect(t, h.do(http.MethodPost, "/api/v1/qr/payment", payment, signPayment(payment)), http.StatusOK, "2005100")
+
+	paid, ok := stream.next()
+	if !ok || paid.change.NewStatus != entity.StatusSuccess || paid.change.OldStatus != entity.StatusPending {
+		t.Fatalf("second event = %+v, want PENDING -> SUCCESS", paid)
+	}
+	if paid.change.EventID <= snapshot.change.EventID || paid.id == snapshot.id {
+		t.Errorf("event ids did not grow: %s then %s", snapshot.id, paid.id)
+	}
+	if stream.heartbeats == 0 {
+		t.Error("no heartbeat was sent while the stream was idle")
+	}
+	if extra, ok := stream.next(); ok {
+		t.Errorf("stream continued after a final status with %+v", extra)
+	}
+
+	// A settled transaction gets its final status and nothing more.
+	resp, err = client.Get(server.URL + streamPath(referenceNo, "MERCHANT-1", time.Now()))
+	if err != nil {
+		t.Fatalf("failed to reopen stream: %v", err)
+	}
+	defer resp.Body.Close()
+	stream = &sseReader{t: t, scanner: bufio.NewScanner(resp.Body)}
+	if final, ok := stream.next(); !ok || final.change.NewStatus != entity.StatusSuccess {
+		t.Fatalf("snapshot of settled transaction = %+v, want SUCCESS", final)
+	}
+	if extra, ok := stream.next(); ok {
+		t.Errorf("settled stream continued with %+v", extra)
+	}
+}
+
+func TestTransactionEventStreamRejections(t *testing.T) {
+	h := newHarness(t)
+	qr := dto.GenerateQRRequest{
+		MerchantID:         "MERCHANT-1",
+		PartnerReferenceNo: "PARTNER-1",
+		Amount:             dto.Amount{Value: "15000.00", Currency: "IDR"},
+	}
+	generated := expect(t, h.do(http.MethodPost, "/api/v1/qr/generate", qr, signQR(qr)), http.StatusOK, "2004700")
+	referenceNo := generated["referenceNo"].(string)
+	valid := streamPath(referenceNo, "MERCHANT-1", time.Now())
+
+	tests := []struct {
+		name         string
+		path         string
+		status       int
+		responseCode string
+	}{
+		{"unsigned", "/api/v1/qr/" + referenceNo + "/events", http.StatusUnauthorized, "4014800"},
+		{"merchantId not matching the key", strings.Replace(valid, "MERCHANT-1", "MERCHANT-2", 1), http.StatusUnauthorized, "4014800"},
+		{"another merchant's transaction", streamPath(referenceNo, "MERCHANT-2", time.Now()), http.StatusNotFound, "4044801"},
+		{"stale timestamp", streamPath(referenceNo, "MERCHANT-1", time.Now().Add(-time.Hour)), http.StatusUnauthorized, "4014800"},
+		{"unknown reference", streamPath("UNKNOWN", "MERCHANT-1", time.Now()), http.StatusNotFound, "4044801"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expect(t, h.do(http.MethodGet, tt.path, nil, ""), tt.status, tt.responseCode)
+		})
+	}
+}
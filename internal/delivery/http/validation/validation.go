@@ -0,0 +1,119 @@
+package validation
+
+import (
+	"errors"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+
+	"payment-gateway-manjo/backend/pkg/snap"
+	"payment-gateway-manjo/backend/pkg/snaptime"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+var (
+	amountPattern     = regexp.MustCompile(`^\d+\.\d{2}$`)
+	merchantIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,50}$`)
+)
+
+var messages = map[string]string{
+	"required":   "is required",
+	"amount":     "must be a decimal with exactly two fraction digits, e.g. 10000.00",
+	"currency":   "must be an ISO-4217 currency code",
+	"merchantid": "must be 1-50 letters, digits, '-' or '_'",
+	"rfc3339":    "must be formatted as YYYY-MM-DDTHH:mm:ss+07:00",
+}
+
+var registerOnce sync.Once
+
+// Register installs the custom rules and JSON field naming on gin's
+// validator. It is safe to call more than once.
+func Register() {
+	registerOnce.Do(func() {
+		v, ok := binding.Validator.Engine().(*validator.Validate)
+		if !ok {
+			return
+		}
+
+		v.RegisterTagNameFunc(jsonFieldName)
+		v.RegisterAlias("currency", "iso4217")
+		v.RegisterValidation("amount", matches(amountPattern))
+		v.RegisterValidation("merchantid", matches(merchantIDPattern))
+		v.RegisterValidation("rfc3339", func(fl validator.FieldLevel) bool {
+			_, err := snaptime.Parse(fl.Field().String())
+			return err == nil
+		})
+	})
+}
+
+// FieldErrors converts a binding error into field-level details and picks the
+// SNAP case that describes it: missing mandatory field when any required
+// field is absent, invalid field format otherwise. ok is false when err is
+// not a validation error, e.g. malformed JSON.
+func FieldErrors(err error) (fieldErrors []snap.FieldError, snapCase snap.Case, ok bool) {
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return nil, snap.Case{}, false
+	}
+
+	snapCase = snap.CaseInvalidFieldFormat
+	for _, fe := range validationErrors {
+		rule := fe.Tag()
+		if rule == "required" {
+			snapCase = snap.CaseMissingMandatoryField
+		}
+		fieldErrors = append(fieldErrors, snap.FieldError{
+			Field:   fieldPath(fe),
+			Rule:    rule,
+			Message: message(fe),
+		})
+	}
+	return fieldErrors, snapCase, true
+}
+
+func matches(pattern *regexp.Regexp) validator.Func {
+	return func(fl validator.FieldLevel) bool {
+		return pattern.MatchString(fl.Field().String())
+	}
+}
+
+func jsonFieldName(field reflect.StructField) string {
+	name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+	if name == "-" {
+		return ""
+	}
+	if name == "" {
+		return field.Name
+	}
+	return name
+}
+
+// fieldPath drops the struct type name from the namespace so nested fields
+// read like the JSON body, e.g. amount.value. Anonymous structs have no type
+// name; there the first segment already differs between the JSON and Go
+// namespaces because it is a field name.
+func fieldPath(fe validator.FieldError) string {
+	namespace := fe.Namespace()
+	root, rest, found := strings.Cut(namespace, ".")
+	if !found {
+		return namespace
+	}
+	if structRoot, _, _ := strings.Cut(fe.StructNamespace(), "."); structRoot != root {
+		return namespace
+	}
+	return rest
+}
+
+func message(fe validator.FieldError) string {
+	rule := fe.Tag()
+	if rule == "oneof" {
+		return "must be one of " + strings.ReplaceAll(fe.Param(), " ", ", ")
+	}
+	if msg, ok := messages[rule]; ok {
+		return msg
+	}
+	return "failed the " + rule + " rule"
+}
@@ -0,0 +1,57 @@
+package worker
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"payment-gateway-manjo/backend/internal/infrastructure/health"
+	"payment-gateway-manjo/backend/internal/infrastructure/metrics"
+	"payment-gateway-manjo/backend/internal/usecase"
+)
+
+type ExpiryWorker struct {
+	paymentUsecase usecase.PaymentUsecase
+	ttl            time.Duration
+	interval       time.Duration
+	batchSize      int
+	heartbeat      *health.Heartbeat
+}
+
+func NewExpiryWorker(paymentUsecase usecase.PaymentUsecase, ttl, interval time.Duration, batchSize int, heartbeat *health.Heartbeat) *ExpiryWorker {
+	return &ExpiryWorker{
+		paymentUsecase: paymentUsecase,
+		ttl:            ttl,
+		interval:       interval,
+		batchSize:      batchSize,
+		heartbeat:      heartbeat,
+	}
+}
+
+// Run expires pending transactions older than the QR TTL until ctx is
+// cancelled. A batch in progress at cancellation is allowed to finish.
+func (w *ExpiryWorker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		expired, err := w.paymentUsecase.ExpireTransactions(context.WithoutCancel(ctx), time.Now().Add(-w.ttl), w.batchSize)
+		metrics.TransactionsExpired.Add(float64(expired))
+		if err != nil {
+			metrics.ExpiryRuns.WithLabelValues("error").Inc()
+			slog.ErrorContext(ctx, "expiry worker run failed", "error", err, "expired", expired)
+		} else {
+			metrics.ExpiryRuns.WithLabelValues("success").Inc()
+			if expired > 0 {
+				slog.InfoContext(ctx, "expiry worker run completed", "expired", expired)
+			}
+		}
+		w.heartbeat.Beat()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package domainerr
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Sentinel kinds shared by every layer. Check them with errors.Is; the
+// delivery layer maps each kind to an HTTP status and SNAP response code.
+var (
+	ErrNotFound       = errors.New("not found")
+	ErrAmountMismatch = errors.New("amount mismatch")
+	ErrInvalidState   = errors.New("invalid state")
+	ErrDuplicate      = errors.New("duplicate")
+	ErrExpired        = errors.New("expired")
+	ErrConflict       = errors.New("conflict")
+	ErrInvalidInput   = errors.New("invalid input")
+)
+
+// Error pairs a kind with a message that is safe to return to clients.
+type Error struct {
+	Kind    error
+	Message string
+}
+
+func New(kind error, format string, args ...interface{}) error {
+	return &Error{Kind: kind, Message: fmt.Sprintf(format, args...)}
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Kind
+}
+
+// Message returns the client-safe message carried by err, or "" if err does
+// not wrap an *Error.
+func Message(err error) string {
+	var domainErr *Error
+	if errors.As(err, &domainErr) {
+		return domainErr.Message
+	}
+	return ""
+}
@@ -0,0 +1,57 @@
+package entity
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+type OutboxEvent struct {
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	AggregateID string     `gorm:"type:varchar(100);not null;index" json:"aggregate_id"`
+	EventType   string     `gorm:"type:varchar(50);not null" json:"event_type"`
+	Payload     string     `gorm:"type:text;not null" json:"payload"`
+	Attempts    int        `gorm:"not null;default:0" json:"attempts"`
+	LastError   string     `gorm:"type:text" json:"last_error,omitempty"`
+	PublishedAt *time.Time `gorm:"index" json:"published_at,omitempty"`
+	// LockedUntil is set while a relay publishes the event; no other relay
+	// claims it before then.
+	LockedUntil *time.Time `json:"-"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+func (OutboxEvent) TableName() string {
+	return "outbox"
+}
+
+const (
+	EventTransactionCreated = "TransactionCreated"
+	EventTransactionPaid    = "TransactionPaid"
+	EventTransactionFailed  = "TransactionFailed"
+	EventTransactionExpired = "TransactionExpired"
+)
+
+func NewOutboxEvent(eventType, aggregateID string, payload interface{}) (*OutboxEvent, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal %s payload: %w", eventType, err)
+	}
+	return &OutboxEvent{
+		AggregateID: aggregateID,
+		EventType:   eventType,
+		Payload:     string(data),
+	}, nil
+}
+
+// EventTypeForStatus returns the event emitted when a transaction moves into status.
+func EventTypeForStatus(status string) (string, bool) {
+	switch status {
+	case StatusSuccess:
+		return EventTransactionPaid, true
+	case StatusFailed:
+		return EventTransactionFailed, true
+	case StatusExpired:
+		return EventTransactionExpired, true
+	}
+	return "", false
+}
@@ -0,0 +1,39 @@
+package entity
+
+import "time"
+
+// StatusChange announces a committed status change to real-time subscribers.
+// EventID is the ID of the matching history entry: it only grows, so clients
+// can resume a stream from the last one they saw.
+type StatusChange struct {
+	EventID                uint      `json:"event_id"`
+	ReferenceNumber        string    `json:"reference_number"`
+	PartnerReferenceNumber string    `json:"partner_reference_number"`
+	MerchantID             string    `json:"merchant_id"`
+	OldStatus              string    `json:"old_status,omitempty"`
+	NewStatus              string    `json:"new_status"`
+	Amount                 float64   `json:"amount"`
+	Currency               string    `json:"currency"`
+	Source                 string    `json:"source"`
+	OccurredAt             time.Time `json:"occurred_at"`
+}
+
+func NewStatusChange(transaction *Transaction, event *TransactionEvent) StatusChange {
+	return StatusChange{
+		EventID:                event.ID,
+		ReferenceNumber:        transaction.ReferenceNumber,
+		PartnerReferenceNumber: transaction.PartnerReferenceNumber,
+		MerchantID:             transaction.MerchantID,
+		OldStatus:              event.OldStatus,
+		NewStatus:              event.NewStatus,
+		Amount:                 transaction.Amount,
+		Currency:               transaction.Currency,
+		Source:                 event.Source,
+		OccurredAt:             event.CreatedAt,
+	}
+}
+
+// Final reports whether the transaction can no longer change status.
+func (c StatusChange) Final() bool {
+	return c.NewStatus != StatusPending
+}
@@ -0,0 +1,45 @@
+package entity
+
+import "time"
+
+type TransactionEvent struct {
+	ID              uint      `gorm:"primaryKey" json:"id"`
+	TransactionID   uint      `gorm:"not null;index" json:"transaction_id"`
+	ReferenceNumber string    `gorm:"type:varchar(100);not null;index" json:"reference_number"`
+	OldStatus       string    `gorm:"type:varchar(20)" json:"old_status"`
+	NewStatus       string    `gorm:"type:varchar(20);not null" json:"new_status"`
+	Source          string    `gorm:"type:varchar(30);not null" json:"source"`
+	PayloadHash     string    `gorm:"type:varchar(64)" json:"payload_hash,omitempty"`
+	Actor           string    `gorm:"type:varchar(100)" json:"actor"`
+	Reason          string    `gorm:"type:varchar(255)" json:"reason,omitempty"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+const (
+	SourceQRGeneration         = "QR_GENERATION"
+	SourceAcquirerNotification = "ACQUIRER_NOTIFICATION"
+	SourceExpiryJob            = "EXPIRY_JOB"
+	SourceAdmin                = "ADMIN"
+)
+
+// AuditInfo describes who or what caused a status change.
+type AuditInfo struct {
+	Source      string
+	Actor       string
+	PayloadHash string
+	// Reason is why an operator changed the status by hand.
+	Reason string
+}
+
+func NewTransactionEvent(transaction *Transaction, oldStatus string, audit AuditInfo) *TransactionEvent {
+	return &TransactionEvent{
+		TransactionID:   transaction.ID,
+		ReferenceNumber: transaction.ReferenceNumber,
+		OldStatus:       oldStatus,
+		NewStatus:       transaction.Status,
+		Source:          audit.Source,
+		PayloadHash:     audit.PayloadHash,
+		Actor:           audit.Actor,
+		Reason:          audit.Reason,
+	}
+}
@@ -0,0 +1,55 @@
+// Package pubsub defines how committed status changes reach real-time
+// subscribers, independent of whether they travel in-process or through
+// the database.
+package pubsub
+
+import (
+	"context"
+	"errors"
+
+	"payment-gateway-manjo/backend/internal/domain/entity"
+)
+
+// ErrSlowConsumer ends a subscription whose buffer filled up. Dropping one
+// change silently would leave the subscriber with a wrong status, so the
+// subscription is closed instead and the subscriber has to catch up from
+// the database.
+var ErrSlowConsumer = errors.New("subscriber fell behind")
+
+// ErrInterrupted ends subscriptions when the broker lost its source of
+// changes for a while, e.g. a dropped database connection, and may have
+// missed some. Like ErrSlowConsumer, the subscriber has to catch up.
+var ErrInterrupted = errors.New("change stream interrupted")
+
+// Filter selects changes by transaction or merchant; empty fields match all.
+type Filter struct {
+	ReferenceNumber string
+	MerchantID      string
+}
+
+func (f Filter) Matches(change entity.StatusChange) bool {
+	return (f.ReferenceNumber == "" || f.ReferenceNumber == change.ReferenceNumber) &&
+		(f.MerchantID == "" || f.MerchantID == change.MerchantID)
+}
+
+type Publisher interface {
+	Publish(ctx context.Context, change entity.StatusChange) error
+}
+
+type Subscription interface {
+	// Events is closed by Close or when the subscriber falls behind.
+	Events() <-chan entity.StatusChange
+	// Err tells why Events was closed: ErrSlowConsumer, ErrInterrupted, or
+	// nil after Close.
+	Err() error
+	Close()
+}
+
+type Subscriber interface {
+	Subscribe(filter Filter) Subscription
+}
+
+type Broker interface {
+	Publisher
+	Subscriber
+}
@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"payment-gateway-manjo/backend/internal/domain/entity"
+)
+
+type OutboxRepository interface {
+	Add(ctx context.Context, event *entity.OutboxEvent) error
+	// ClaimPending leases up to limit unpublished events to the caller until
+	// the given time, skipping events another relay holds a lease on.
+	ClaimPending(ctx context.Context, limit, maxAttempts int, until time.Time) ([]entity.OutboxEvent, error)
+	MarkPublished(ctx context.Context, id uint, publishedAt time.Time) error
+	MarkFailed(ctx context.Context, id uint, reason string) error
+}
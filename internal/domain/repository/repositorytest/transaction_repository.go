@@ -0,0 +1,238 @@
+// Package repositorytest holds behaviour shared by every repository
+// implementation, so the database and in-memory versions cannot drift apart.
+package repositorytest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"payment-gateway-manjo/backend/internal/domain/domainerr"
+	"payment-gateway-manjo/backend/internal/domain/entity"
+	"payment-gateway-manjo/backend/internal/domain/repository"
+)
+
+// base is a fixed instant with whole seconds, so values survive a round trip
+// through a timestamptz column unchanged.
+var base = time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+
+// RunTransactionRepository checks the TransactionRepository contract. newRepo
+// must return a repository with no stored transactions.
+func RunTransactionRepository(t *testing.T, newRepo func(t *testing.T) repository.TransactionRepository) {
+	ctx := context.Background()
+
+	t.Run("create and find", func(t *testing.T) {
+		repo := newRepo(t)
+		created := newTransaction("M1", "P-1", "R-1", entity.StatusPending, base)
+		if err := repo.Create(ctx, created); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+		if created.ID == 0 {
+			t.Fatal("Create() did not assign an ID")
+		}
+
+		byRef, err := repo.FindByReferenceNumber(ctx, "R-1")
+		if err != nil {
+			t.Fatalf("FindByReferenceNumber() error = %v", err)
+		}
+		byPartnerRef, err := repo.FindByPartnerReferenceNumber(ctx, "P-1")
+		if err != nil {
+			t.Fatalf("FindByPartnerReferenceNumber() error = %v", err)
+		}
+		for _, found := range []*entity.Transaction{byRef, byPartnerRef} {
+			if found.ID != created.ID || found.MerchantID != "M1" || found.Amount != 10000 ||
+				found.Status != entity.StatusPending || !found.TransactionDate.Equal(base) {
+				t.Errorf("found %+v, want the created transaction", found)
+			}
+		}
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		repo := newRepo(t)
+		if _, err := repo.FindByReferenceNumber(ctx, "missing"); !errors.Is(err, domainerr.ErrNotFound) {
+			t.Errorf("FindByReferenceNumber() error = %v, want ErrNotFound", err)
+		}
+		if _, err := repo.FindByPartnerReferenceNumber(ctx, "missing"); !errors.Is(err, domainerr.ErrNotFound) {
+			t.Errorf("FindByPartnerReferenceNumber() error = %v, want ErrNotFound", err)
+		}
+	})
+
+	t.Run("unique reference numbers", func(t *testing.T) {
+		tests := []struct {
+			name   string
+			second *entity.Transaction
+		}{
+			{"duplicate partner reference number", newTransaction("M2", "P-1", "R-2", entity.StatusPending, base)},
+			{"duplicate reference number", newTransaction("M2", "P-2", "R-1", entity.StatusPending, base)},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				repo := newRepo(t)
+				mustCreate(t, repo, newTransaction("M1", "P-1", "R-1", entity.StatusPending, base))
+
+				if err := repo.Create(ctx, tt.second); !errors.Is(err, domainerr.ErrDuplicate) {
+					t.Fatalf("Create() error = %v, want ErrDuplicate", err)
+				}
+				all, err := repo.FindAll(ctx)
+				if err != nil {
+					t.Fatalf("FindAll() error = %v", err)
+				}
+				if len(all) != 1 {
+					t.Errorf("FindAll() returned %d transactions, want 1", len(all))
+				}
+			})
+		}
+	})
+
+	t.Run("update with expected status", func(t *testing.T) {
+		repo := newRepo(t)
+		transaction := newTransaction("M1", "P-1", "R-1", entity.StatusPending, base)
+		mustCreate(t, repo, transaction)
+
+		paidAt := base.Add(time.Minute)
+		transaction.Status = entity.StatusSuccess
+		transaction.PaidDate = &paidAt
+		if err := repo.Update(ctx, transaction, entity.StatusPending); err != nil {
+			t.Fatalf("Update() error = %v", err)
+		}
+
+		stored, err := repo.FindByReferenceNumber(ctx, "R-1")
+		if err != nil {
+			t.Fatalf("FindByReferenceNumber() error = %v", err)
+		}
+		if stored.Status != entity.StatusSuccess || stored.PaidDate == nil || !stored.PaidDate.Equal(paidAt) {
+			t.Errorf("stored %+v, want SUCCESS paid at %v", stored, paidAt)
+		}
+	})
+
+	t.Run("update with stale status conflicts", func(t *testing.T) {
+		repo := newRepo(t)
+		transaction := newTransaction("M1", "P-1", "R-1", entity.StatusPending, base)
+		mustCreate(t, repo, transaction)
+
+		transaction.Status = entity.StatusExpired
+		err := repo.Update(ctx, transaction, entity.StatusSuccess)
+		if !errors.Is(err, domainerr.ErrConflict) {
+			t.Fatalf("Update() error = %v, want ErrConflict", err)
+		}
+
+		stored, err := repo.FindByReferenceNumber(ctx, "R-1")
+		if err != nil {
+			t.Fatalf("FindByReferenceNumber() error = %v", err)
+		}
+		if stored.Status != entity.StatusPending {
+			t.Errorf("status = %s after a conflicting update, want PENDING", stored.Status)
+		}
+	})
+
+	t.Run("update into a taken partner reference number", func(t *testing.T) {
+		repo := newRepo(t)
+		mustCreate(t, repo, newTransaction("M1", "P-1", "R-1", entity.StatusPending, base))
+		second := newTransaction("M1", "P-2", "R-2", entity.StatusPending, base)
+		mustCreate(t, repo, second)
+
+		second.PartnerReferenceNumber = "P-1"
+		if err := repo.Update(ctx, second, entity.StatusPending); !errors.Is(err, domainerr.ErrDuplicate) {
+			t.Errorf("Update() error = %v, want ErrDuplicate", err)
+		}
+	})
+
+	t.Run("filters", func(t *testing.T) {
+		repo := newRepo(t)
+		mustCreate(t, repo, newTransaction("M1", "P-1", "R-1", entity.StatusPending, base))
+		mustCreate(t, repo, newTransaction("M1", "P-2", "R-2", entity.StatusSuccess, base.Add(time.Minute)))
+		mustCreate(t, repo, newTransaction("M2", "P-3", "R-3", entity.StatusSuccess, base.Add(2*time.Minute)))
+		mustCreate(t, repo, newTransaction("M2", "P-4", "R-4", entity.StatusExpired, base.Add(3*time.Minute)))
+
+		tests := []struct {
+			name                                    string
+			merchantID, partnerRefNo, refNo, status string
+			want                                    []string
+		}{
+			{name: "no filters", want: []string{"R-4", "R-3", "R-2", "R-1"}},
+			{name: "merchant", merchantID: "M1", want: []string{"R-2", "R-1"}},
+			{name: "status", status: entity.StatusSuccess, want: []string{"R-3", "R-2"}},
+			{name: "merchant and status", merchantID: "M2", status: entity.StatusSuccess, want: []string{"R-3"}},
+			{name: "partner reference number", partnerRefNo: "P-4", want: []string{"R-4"}},
+			{name: "reference number", refNo: "R-1", want: []string{"R-1"}},
+			{name: "reference number of another merchant", merchantID: "M1", refNo: "R-3", want: nil},
+			{name: "no match", status: entity.StatusFailed, want: nil},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				found, err := repo.FindByFilters(ctx, tt.merchantID, tt.partnerRefNo, tt.refNo, tt.status)
+				if err != nil {
+					t.Fatalf("FindByFilters() error = %v", err)
+				}
+				assertReferences(t, found, tt.want)
+			})
+		}
+
+		all, err := repo.FindAll(ctx)
+		if err != nil {
+			t.Fatalf("FindAll() error = %v", err)
+		}
+		assertReferences(t, all, []string{"R-4", "R-3", "R-2", "R-1"})
+	})
+
+	t.Run("pending before cutoff", func(t *testing.T) {
+		repo := newRepo(t)
+		mustCreate(t, repo, newTransaction("M1", "P-1", "R-1", entity.StatusPending, base.Add(2*time.Minute)))
+		mustCreate(t, repo, newTransaction("M1", "P-2", "R-2", entity.StatusPending, base))
+		mustCreate(t, repo, newTransaction("M1", "P-3", "R-3", entity.StatusSuccess, base))
+		mustCreate(t, repo, newTransaction("M1", "P-4", "R-4", entity.StatusPending, base.Add(time.Minute)))
+		mustCreate(t, repo, newTransaction("M1", "P-5", "R-5", entity.StatusPending, base.Add(time.Hour)))
+
+		cutoff := base.Add(30 * time.Minute)
+		found, err := repo.FindPendingBefore(ctx, cutoff, 10)
+		if err != nil {
+			t.Fatalf("FindPendingBefore() error = %v", err)
+		}
+		assertReferences(t, found, []string{"R-2", "R-4", "R-1"})
+
+		limited, err := repo.FindPendingBefore(ctx, cutoff, 2)
+		if err != nil {
+			t.Fatalf("FindPendingBefore() error = %v", err)
+		}
+		assertReferences(t, limited, []string{"R-2", "R-4"})
+	})
+}
+
+// newTransaction builds a transaction whose creation time follows its
+// transaction date, so listings sort the same way in every implementation.
+func newTransaction(merchantID, partnerRefNo, refNo, status string, transactionDate time.Time) *entity.Transaction {
+	return &entity.Transaction{
+		MerchantID:             merchantID,
+		Amount:                 10000,
+		Currency:               "IDR",
+		PartnerReferenceNumber: partnerRefNo,
+		ReferenceNumber:        refNo,
+		Status:                 status,
+		TransactionDate:        transactionDate,
+		CreatedAt:              transactionDate,
+	}
+}
+
+func mustCreate(t *testing.T, repo repository.TransactionRepository, transaction *entity.Transaction) {
+	t.Helper()
+	if err := repo.Create(context.Background(), transaction); err != nil {
+		t.Fatalf("Create(%s) error = %v", transaction.ReferenceNumber, err)
+	}
+}
+
+func assertReferences(t *testing.T, transactions []entity.Transaction, want []string) {
+	t.Helper()
+	got := make([]string, len(transactions))
+	for i, transaction := range transactions {
+		got[i] = transaction.ReferenceNumber
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got references %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("got references %v, want %v", got, want)
+		}
+	}
+}
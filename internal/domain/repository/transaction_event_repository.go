@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"context"
+
+	"payment-gateway-manjo/backend/internal/domain/entity"
+)
+
+type TransactionEventRepository interface {
+	Create(ctx context.Context, event *entity.TransactionEvent) error
+	FindByTransactionID(ctx context.Context, transactionID uint) ([]entity.TransactionEvent, error)
+	// FindChangesByMerchant returns up to limit changes to the merchant's
+	// transactions whose event ID is above afterID, in ID order.
+	FindChangesByMerchant(ctx context.Context, merchantID string, afterID uint, limit int) ([]entity.StatusChange, error)
+}
@@ -1,12 +1,18 @@
 package repository
 
-import "payment-gateway-manjo/backend/internal/domain/entity"
+import (
+	"context"
+	"time"
+
+	"payment-gateway-manjo/backend/internal/domain/entity"
+)
 
 type TransactionRepository interface {
-	Create(transaction *entity.Transaction) error
-	FindByReferenceNumber(referenceNumber string) (*entity.Transaction, error)
-	FindByPartnerReferenceNumber(partnerRefNo string) (*entity.Transaction, error)
-	Update(transaction *entity.Transaction) error
-	FindAll() ([]entity.Transaction, error)
-	FindByFilters(merchantID, partnerRefNo, refNo, status string) ([]entity.Transaction, error)
-}
\ No newline at end of file
+	Create(ctx context.Context, transaction *entity.Transaction) error
+	FindByReferenceNumber(ctx context.Context, referenceNumber string) (*entity.Transaction, error)
+	FindByPartnerReferenceNumber(ctx context.Context, partnerRefNo string) (*entity.Transaction, error)
+	Update(ctx context.Context, transaction *entity.Transaction, expectedStatus string) error
+	FindAll(ctx context.Context) ([]entity.Transaction, error)
+	FindByFilters(ctx context.Context, merchantID, partnerRefNo, refNo, status string) ([]entity.Transaction, error)
+	FindPendingBefore(ctx context.Context, cutoff time.Time, limit int) ([]entity.Transaction, error)
+}
@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"context"
+
+	"payment-gateway-manjo/backend/internal/domain/pubsub"
+)
+
+// Repositories exposes repositories bound to a single database transaction.
+type Repositories interface {
+	Transactions() TransactionRepository
+	Outbox() OutboxRepository
+	TransactionEvents() TransactionEventRepository
+	// StatusChanges publishes as part of the transaction: subscribers only
+	// see changes that commit.
+	StatusChanges() pubsub.Publisher
+}
+
+// Transactor runs fn inside a database transaction, committing when fn
+// returns nil and rolling back otherwise.
+type Transactor interface {
+	WithinTransaction(ctx context.Context, fn func(repos Repositories) error) error
+}
@@ -0,0 +1,118 @@
+// Package broker implements pubsub.Broker.
+package broker
+
+import (
+	"context"
+	"sync"
+
+	"payment-gateway-manjo/backend/internal/domain/entity"
+	"payment-gateway-manjo/backend/internal/domain/pubsub"
+)
+
+// Broker is a pubsub.Broker that can also end every subscription at once,
+// e.g. so open streams do not hold up a graceful shutdown.
+type Broker interface {
+	pubsub.Broker
+	Interrupt(err error)
+}
+
+// MemoryBroker delivers changes to subscribers in the same process. It is
+// enough for a single replica; with several, a change committed by one is
+// only seen by clients connected to it.
+type MemoryBroker struct {
+	mu            sync.RWMutex
+	subscriptions map[*subscription]struct{}
+	bufferSize    int
+}
+
+func NewMemoryBroker(bufferSize int) *MemoryBroker {
+	return &MemoryBroker{
+		subscriptions: make(map[*subscription]struct{}),
+		bufferSize:    bufferSize,
+	}
+}
+
+// Publish never blocks on a subscriber: one whose buffer is full is closed
+// with pubsub.ErrSlowConsumer.
+func (b *MemoryBroker) Publish(ctx context.Context, change entity.StatusChange) error {
+	var lagging []*subscription
+
+	b.mu.RLock()
+	for sub := range b.subscriptions {
+		if !sub.filter.Matches(change) {
+			continue
+		}
+		select {
+		case sub.events <- change:
+		default:
+			lagging = append(lagging, sub)
+		}
+	}
+	b.mu.RUnlock()
+
+	for _, sub := range lagging {
+		b.remove(sub, pubsub.ErrSlowConsumer)
+	}
+	return nil
+}
+
+func (b *MemoryBroker) Subscribe(filter pubsub.Filter) pubsub.Subscription {
+	sub := &subscription{
+		broker: b,
+		filter: filter,
+		events: make(chan entity.StatusChange, b.bufferSize),
+	}
+	b.mu.Lock()
+	b.subscriptions[sub] = struct{}{}
+	b.mu.Unlock()
+	return sub
+}
+
+// Interrupt closes every subscription with err, telling subscribers that
+// changes may have been missed.
+func (b *MemoryBroker) Interrupt(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for sub := range b.subscriptions {
+		delete(b.subscriptions, sub)
+		sub.err = err
+		close(sub.events)
+	}
+}
+
+// remove closes sub once. Holding the write lock guarantees no Publish is
+// sending on the channel while it is closed.
+func (b *MemoryBroker) remove(sub *subscription, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, ok := b.subscriptions[sub]; !ok {
+		return
+	}
+	delete(b.subscriptions, sub)
+	sub.err = err
+	close(sub.events)
+}
+
+type subscription struct {
+	broker *MemoryBroker
+	filter pubsub.Filter
+	events chan entity.StatusChange
+	// err is guarded by broker.mu.
+	err error
+}
+
+func (s *subscription) Events() <-chan entity.StatusChange {
+	return s.events
+}
+
+func (s *subscription) Err() error {
+	s.broker.mu.RLock()
+	defer s.broker.mu.RUnlock()
+	return s.err
+}
+
+func (s *subscription) Close() {
+	s.broker.remove(s, nil)
+}
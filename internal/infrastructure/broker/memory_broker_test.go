@@ -0,0 +1,65 @@
+package broker
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"payment-gateway-manjo/backend/internal/domain/entity"
+	"payment-gateway-manjo/backend/internal/domain/pubsub"
+)
+
+func TestMemoryBrokerFiltersAndDropsSlowConsumers(t *testing.T) {
+	ctx := context.Background()
+	b := NewMemoryBroker(1)
+
+	mine := b.Subscribe(pubsub.Filter{ReferenceNumber: "REF-1"})
+	merchant := b.Subscribe(pubsub.Filter{MerchantID: "M1"})
+	defer merchant.Close()
+
+	b.Publish(ctx, entity.StatusChange{EventID: 1, ReferenceNumber: "REF-2", MerchantID: "M2"})
+	b.Publish(ctx, entity.StatusChange{EventID: 2, ReferenceNumber: "REF-1", MerchantID: "M1"})
+
+	if got := <-mine.Events(); got.EventID != 2 {
+		t.Fatalf("reference subscriber got event %d, want 2", got.EventID)
+	}
+
+	// merchant still holds event 2 in its one-slot buffer.
+	b.Publish(ctx, entity.StatusChange{EventID: 3, ReferenceNumber: "REF-3", MerchantID: "M1"})
+	<-merchant.Events()
+	if _, ok := <-merchant.Events(); ok {
+		t.Fatal("lagging subscriber was not closed")
+	}
+	if !errors.Is(merchant.Err(), pubsub.ErrSlowConsumer) {
+		t.Errorf("Err() = %v, want ErrSlowConsumer", merchant.Err())
+	}
+
+	mine.Close()
+	mine.Close()
+	if _, ok := <-mine.Events(); ok || mine.Err() != nil {
+		t.Errorf("closed subscription: open=%v err=%v, want closed without error", ok, mine.Err())
+	}
+	b.Publish(ctx, entity.StatusChange{EventID: 4, ReferenceNumber: "REF-1"})
+}
+
+func TestMemoryBrokerInterrupt(t *testing.T) {
+	b := NewMemoryBroker(1)
+	first := b.Subscribe(pubsub.Filter{})
+	second := b.Subscribe(pubsub.Filter{MerchantID: "M1"})
+
+	b.Interrupt(pubsub.ErrInterrupted)
+	for _, sub := range []pubsub.Subscription{first, second} {
+		if _, ok := <-sub.Events(); ok || !errors.Is(sub.Err(), pubsub.ErrInterrupted) {
+			t.Errorf("subscription open=%v err=%v, want closed with ErrInterrupted", ok, sub.Err())
+		}
+		sub.Close()
+	}
+
+	// New subscriptions are unaffected.
+	third := b.Subscribe(pubsub.Filter{})
+	defer third.Close()
+	b.Publish(context.Background(), entity.StatusChange{EventID: 1})
+	if got := <-third.Events(); got.EventID != 1 {
+		t.Errorf("got event %d after interrupt, want 1", got.EventID)
+	}
+}
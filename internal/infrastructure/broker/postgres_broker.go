@@ -0,0 +1,149 @@
+package broker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	"payment-gateway-manjo/backend/internal/domain/entity"
+	"payment-gateway-manjo/backend/internal/domain/pubsub"
+	"payment-gateway-manjo/backend/internal/infrastructure/database"
+	"payment-gateway-manjo/backend/internal/infrastructure/metrics"
+
+	"github.com/jackc/pgx/v5"
+	"gorm.io/gorm"
+)
+
+const (
+	// listenerPing is how long the listener waits for a notification before
+	// checking that its connection is still alive.
+	listenerPing     = 30 * time.Second
+	reconnectInitial = 500 * time.Millisecond
+	reconnectMax     = 30 * time.Second
+)
+
+// PostgresBroker fans status changes out across replicas with LISTEN/NOTIFY.
+// Changes are published with pg_notify, normally by a notifying transactor
+// inside the transaction that makes them, and every replica's listener hands
+// them to its local subscribers.
+type PostgresBroker struct {
+	db        *gorm.DB
+	dsn       string
+	channel   string
+	local     *MemoryBroker
+	connected atomic.Bool
+}
+
+// NewPostgresBroker publishes through db and listens on a connection of its
+// own to dsn, which must reach the primary: notifications are not sent to
+// replicas.
+func NewPostgresBroker(db *gorm.DB, dsn, channel string, bufferSize int) *PostgresBroker {
+	return &PostgresBroker{
+		db:      db,
+		dsn:     dsn,
+		channel: channel,
+		local:   NewMemoryBroker(bufferSize),
+	}
+}
+
+// Publish notifies outside any transaction; prefer publishing through the
+// transaction that makes the change.
+func (b *PostgresBroker) Publish(ctx context.Context, change entity.StatusChange) error {
+	return database.Notify(ctx, b.db, b.channel, change)
+}
+
+func (b *PostgresBroker) Subscribe(filter pubsub.Filter) pubsub.Subscription {
+	return b.local.Subscribe(filter)
+}
+
+func (b *PostgresBroker) Interrupt(err error) {
+	b.local.Interrupt(err)
+}
+
+// Check reports whether the listener is connected, for the readiness probe.
+func (b *PostgresBroker) Check(ctx context.Context) error {
+	if !b.connected.Load() {
+		return errors.New("not listening for status changes")
+	}
+	return nil
+}
+
+// Run listens until ctx is cancelled, reconnecting with backoff whenever the
+// connection fails. Notifications sent while it was down are lost, so after
+// reconnecting every subscription is ended with pubsub.ErrInterrupted and
+// subscribers catch up from the database.
+func (b *PostgresBroker) Run(ctx context.Context) {
+	backoff := reconnectInitial
+	for attempt := 0; ; attempt++ {
+		listening := false
+		err := b.listen(ctx, func() {
+			listening = true
+			if attempt > 0 {
+				metrics.RealtimeListenerReconnects.Inc()
+				b.local.Interrupt(pubsub.ErrInterrupted)
+				slog.InfoContext(ctx, "status change listener reconnected", "channel", b.channel)
+			}
+		})
+		if ctx.Err() != nil {
+			b.local.Interrupt(pubsub.ErrInterrupted)
+			return
+		}
+		if listening {
+			backoff = reconnectInitial
+		}
+		slog.ErrorContext(ctx, "status change listener failed", "channel", b.channel, "error", err, "retry_in", backoff)
+
+		select {
+		case <-ctx.Done():
+			b.local.Interrupt(pubsub.ErrInterrupted)
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, reconnectMax)
+	}
+}
+
+// listen runs one LISTEN session, calling ready once it is established, and
+// returns when the connection fails or ctx is cancelled.
+func (b *PostgresBroker) listen(ctx context.Context, ready func()) error {
+	conn, err := pgx.Connect(ctx, b.dsn)
+	if err != nil {
+		return fmt.Errorf("failed to connect: %w", err)
+	}
+	defer conn.Close(context.WithoutCancel(ctx))
+
+	if _, err := conn.Exec(ctx, "LISTEN "+pgx.Identifier{b.channel}.Sanitize()); err != nil {
+		return fmt.Errorf("failed to listen: %w", err)
+	}
+	b.connected.Store(true)
+	defer b.connected.Store(false)
+	ready()
+
+	for {
+		waitCtx, cancel := context.WithTimeout(ctx, listenerPing)
+		notification, err := conn.WaitForNotification(waitCtx)
+		cancel()
+		switch {
+		case ctx.Err() != nil:
+			return ctx.Err()
+		case errors.Is(err, context.DeadlineExceeded):
+			if err := conn.Ping(ctx); err != nil {
+				return fmt.Errorf("listener connection is dead: %w", err)
+			}
+			continue
+		case err != nil:
+			return fmt.Errorf("failed to wait for notification: %w", err)
+		}
+
+		var change entity.StatusChange
+		if err := json.Unmarshal([]byte(notification.Payload), &change); err != nil {
+			slog.WarnContext(ctx, "ignoring malformed status change notification", "channel", b.channel, "error", err)
+			continue
+		}
+		_ = b.local.Publish(ctx, change)
+	}
+}
@@ -1,70 +1,228 @@
 package config
 
 import (
-    "fmt"
-    "os"
-    "strconv"
-
-    "github.com/joho/godotenv"
+	"time"
 )
 
 type Config struct {
-    Database DatabaseConfig
-    Server   ServerConfig
-    Security SecurityConfig
+	Database DatabaseConfig
+	Server   ServerConfig
+	CORS     CORSConfig
+	Security SecurityConfig
+	Outbox   OutboxConfig
+	Expiry   ExpiryConfig
+	Payment  PaymentConfig
+	Timeouts TimeoutConfig
+	Realtime RealtimeConfig
+	Tracing  TracingConfig
+	Log      LogConfig
+	Features FeatureConfig
 }
 
 type DatabaseConfig struct {
-    Host     string
-    Port     string
-    User     string
-    Password string
-    DBName   string
-    SSLMode  string
+	Host             string
+	Port             string
+	User             string
+	Password         string
+	DBName           string
+	SSLMode          string
+	MaxOpenConns     int
+	MaxIdleConns     int
+	ConnMaxLifetime  time.Duration
+	ConnMaxIdleTime  time.Duration
+	StatementTimeout time.Duration
+	Replica          ReplicaConfig
+}
+
+// ReplicaConfig points listing and reporting queries at a read replica. It is
+// disabled while Host is empty; unset fields fall back to the primary's.
+type ReplicaConfig struct {
+	Host     string
+	Port     string
+	User     string
+	Password string
 }
 
 type ServerConfig struct {
-    Port string
+	Port              string
+	TrustedProxies    []string
+	ReadTimeout       time.Duration
+	ReadHeaderTimeout time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+	ShutdownTimeout   time.Duration
+	ShutdownDelay     time.Duration
+	HealthTimeout     time.Duration
+	MaxHeaderBytes    int
+}
+
+type CORSConfig struct {
+	AllowOrigins     []string
+	AllowCredentials bool
 }
 
 type SecurityConfig struct {
-    SecretKey string
-}
-
-func LoadConfig() (*Config, error) {
-    if err := godotenv.Load(); err != nil {
-        return nil, fmt.Errorf("failed to load .env file: %v", err)
-    }
-
-    cfg := &Config{
-        Database: DatabaseConfig{
-            Host:     os.Getenv("DATABASE_HOST"),
-            Port:     os.Getenv("DATABASE_PORT"),
-            User:     os.Getenv("DATABASE_USER"),
-            Password: os.Getenv("DATABASE_PASSWORD"),
-            DBName:   os.Getenv("DATABASE_NAME"),
-            SSLMode:  os.Getenv("DATABASE_SSL_MODE"),
-        },
-        Server: ServerConfig{
-            Port: os.Getenv("SERVER_PORT"),
-        },
-        Security: SecurityConfig{
-            SecretKey: os.Getenv("SECRET_KEY"),
-        },
-    }
-
-    if cfg.Database.Password == "" {
-        return nil, fmt.Errorf("DATABASE_PASSWORD is required")
-    }
-    if cfg.Security.SecretKey == "" {
-        return nil, fmt.Errorf("SECRET_KEY is required")
-    }
-    if _, err := strconv.Atoi(cfg.Server.Port); err != nil {
-        return nil, fmt.Errorf("SERVER_PORT must be numeric: %v", err)
-    }
-    if _, err := strconv.Atoi(cfg.Database.Port); err != nil {
-        return nil, fmt.Errorf("DATABASE_PORT must be numeric: %v", err)
-    }
-
-    return cfg, nil
+	SecretKey string
+	// SecretKeyNext is accepted alongside SecretKey while partners move to
+	// a new key; see gatewayctl keys rotate.
+	SecretKeyNext string
+	// LegacySignatures also accepts X-Signature computed over the old
+	// field-based string rather than the raw body, while partners migrate.
+	// That string leaves paidTime, currency and originalPartnerReferenceNo
+	// unsigned, so it is opt-in, and support is removed on 2027-03-31.
+	LegacySignatures bool
+	MaxBodyBytes     int64
+	// FeedSecret derives each merchant's feed signing key. It is kept apart
+	// from SecretKey so a merchant's key reveals nothing about the secret
+	// that signs payment notifications.
+	FeedSecret string
+	// FeedSignatureMaxAge is how far a merchant feed signature's timestamp
+	// may be from now, in either direction.
+	FeedSignatureMaxAge time.Duration
+}
+
+type OutboxConfig struct {
+	Sinks          []string
+	WebhookURL     string
+	WebhookSecret  string
+	WebhookTimeout time.Duration
+	PollInterval   time.Duration
+	BatchSize      int
+	MaxAttempts    int
+	Lease          time.Duration
+}
+
+type ExpiryConfig struct {
+	QRTTL     time.Duration
+	Interval  time.Duration
+	BatchSize int
+}
+
+type PaymentConfig struct {
+	PaidTimeMaxSkew time.Duration
+}
+
+type TimeoutConfig struct {
+	GenerateQR     time.Duration
+	ProcessPayment time.Duration
+	Query          time.Duration
+	ExpireBatch    time.Duration
+}
+
+// RealtimeConfig tunes the status streams pushed to connected clients.
+type RealtimeConfig struct {
+	// Broker is memory for a single replica or postgres to fan changes out
+	// to every replica through LISTEN/NOTIFY on Channel.
+	Broker     string
+	Channel    string
+	Heartbeat  time.Duration
+	BufferSize int
+}
+
+const (
+	BrokerMemory   = "memory"
+	BrokerPostgres = "postgres"
+)
+
+type TracingConfig struct {
+	Exporter     string
+	OTLPEndpoint string
+	ServiceName  string
+	SampleRatio  float64
+}
+
+type LogConfig struct {
+	Level string
+}
+
+// FeatureConfig switches optional parts of the process on or off, e.g. to run
+// the background workers in a separate deployment from the API. The expiry
+// worker is opt-in.
+type FeatureConfig struct {
+	OutboxRelay  bool
+	ExpiryWorker bool
+	Metrics      bool
+}
+
+// setting binds one configuration key to a field of Config. The key is the
+// environment variable name; flags and file keys are derived from it.
+type setting struct {
+	key    string
+	def    string
+	target func(cfg *Config) interface{}
+}
+
+var settings = []setting{
+	{"DATABASE_HOST", "localhost", func(c *Config) interface{} { return &c.Database.Host }},
+	{"DATABASE_PORT", "5432", func(c *Config) interface{} { return &c.Database.Port }},
+	{"DATABASE_USER", "postgres", func(c *Config) interface{} { return &c.Database.User }},
+	{"DATABASE_PASSWORD", "", func(c *Config) interface{} { return &c.Database.Password }},
+	{"DATABASE_NAME", "payment_gateway_manjo", func(c *Config) interface{} { return &c.Database.DBName }},
+	{"DATABASE_SSL_MODE", "disable", func(c *Config) interface{} { return &c.Database.SSLMode }},
+	{"DATABASE_MAX_OPEN_CONNS", "25", func(c *Config) interface{} { return &c.Database.MaxOpenConns }},
+	{"DATABASE_MAX_IDLE_CONNS", "10", func(c *Config) interface{} { return &c.Database.MaxIdleConns }},
+	{"DATABASE_CONN_MAX_LIFETIME", "30m", func(c *Config) interface{} { return &c.Database.ConnMaxLifetime }},
+	{"DATABASE_CONN_MAX_IDLE_TIME", "5m", func(c *Config) interface{} { return &c.Database.ConnMaxIdleTime }},
+	{"DATABASE_STATEMENT_TIMEOUT", "30s", func(c *Config) interface{} { return &c.Database.StatementTimeout }},
+	{"DATABASE_REPLICA_HOST", "", func(c *Config) interface{} { return &c.Database.Replica.Host }},
+	{"DATABASE_REPLICA_PORT", "", func(c *Config) interface{} { return &c.Database.Replica.Port }},
+	{"DATABASE_REPLICA_USER", "", func(c *Config) interface{} { return &c.Database.Replica.User }},
+	{"DATABASE_REPLICA_PASSWORD", "", func(c *Config) interface{} { return &c.Database.Replica.Password }},
+
+	{"SERVER_PORT", "8080", func(c *Config) interface{} { return &c.Server.Port }},
+	{"SERVER_TRUSTED_PROXIES", "", func(c *Config) interface{} { return &c.Server.TrustedProxies }},
+	{"SERVER_READ_TIMEOUT", "15s", func(c *Config) interface{} { return &c.Server.ReadTimeout }},
+	{"SERVER_READ_HEADER_TIMEOUT", "5s", func(c *Config) interface{} { return &c.Server.ReadHeaderTimeout }},
+	{"SERVER_WRITE_TIMEOUT", "30s", func(c *Config) interface{} { return &c.Server.WriteTimeout }},
+	{"SERVER_IDLE_TIMEOUT", "60s", func(c *Config) interface{} { return &c.Server.IdleTimeout }},
+	{"SERVER_SHUTDOWN_TIMEOUT", "30s", func(c *Config) interface{} { return &c.Server.ShutdownTimeout }},
+	{"SERVER_SHUTDOWN_DELAY", "5s", func(c *Config) interface{} { return &c.Server.ShutdownDelay }},
+	{"SERVER_HEALTH_TIMEOUT", "2s", func(c *Config) interface{} { return &c.Server.HealthTimeout }},
+	{"SERVER_MAX_HEADER_BYTES", "1048576", func(c *Config) interface{} { return &c.Server.MaxHeaderBytes }},
+
+	{"CORS_ALLOW_ORIGINS", "http://localhost:3000,http://localhost:5173", func(c *Config) interface{} { return &c.CORS.AllowOrigins }},
+	{"CORS_ALLOW_CREDENTIALS", "true", func(c *Config) interface{} { return &c.CORS.AllowCredentials }},
+
+	{"SECRET_KEY", "", func(c *Config) interface{} { return &c.Security.SecretKey }},
+	{"SECRET_KEY_NEXT", "", func(c *Config) interface{} { return &c.Security.SecretKeyNext }},
+	{"SECURITY_LEGACY_SIGNATURES", "false", func(c *Config) interface{} { return &c.Security.LegacySignatures }},
+	{"SECURITY_MAX_BODY_BYTES", "1048576", func(c *Config) interface{} { return &c.Security.MaxBodyBytes }},
+	{"SECURITY_FEED_SECRET", "", func(c *Config) interface{} { return &c.Security.FeedSecret }},
+	{"SECURITY_FEED_SIGNATURE_MAX_AGE", "5m", func(c *Config) interface{} { return &c.Security.FeedSignatureMaxAge }},
+
+	{"OUTBOX_SINKS", "log", func(c *Config) interface{} { return &c.Outbox.Sinks }},
+	{"OUTBOX_WEBHOOK_URL", "", func(c *Config) interface{} { return &c.Outbox.WebhookURL }},
+	{"OUTBOX_WEBHOOK_SECRET", "", func(c *Config) interface{} { return &c.Outbox.WebhookSecret }},
+	{"OUTBOX_WEBHOOK_TIMEOUT", "5s", func(c *Config) interface{} { return &c.Outbox.WebhookTimeout }},
+	{"OUTBOX_POLL_INTERVAL", "2s", func(c *Config) interface{} { return &c.Outbox.PollInterval }},
+	{"OUTBOX_BATCH_SIZE", "100", func(c *Config) interface{} { return &c.Outbox.BatchSize }},
+	{"OUTBOX_MAX_ATTEMPTS", "10", func(c *Config) interface{} { return &c.Outbox.MaxAttempts }},
+	{"OUTBOX_LEASE", "1m", func(c *Config) interface{} { return &c.Outbox.Lease }},
+
+	{"EXPIRY_QR_TTL", "15m", func(c *Config) interface{} { return &c.Expiry.QRTTL }},
+	{"EXPIRY_INTERVAL", "1m", func(c *Config) interface{} { return &c.Expiry.Interval }},
+	{"EXPIRY_BATCH_SIZE", "100", func(c *Config) interface{} { return &c.Expiry.BatchSize }},
+
+	{"PAYMENT_PAID_TIME_MAX_SKEW", "5m", func(c *Config) interface{} { return &c.Payment.PaidTimeMaxSkew }},
+
+	{"TIMEOUT_GENERATE_QR", "5s", func(c *Config) interface{} { return &c.Timeouts.GenerateQR }},
+	{"TIMEOUT_PROCESS_PAYMENT", "10s", func(c *Config) interface{} { return &c.Timeouts.ProcessPayment }},
+	{"TIMEOUT_QUERY", "5s", func(c *Config) interface{} { return &c.Timeouts.Query }},
+	{"TIMEOUT_EXPIRE_BATCH", "30s", func(c *Config) interface{} { return &c.Timeouts.ExpireBatch }},
+
+	{"REALTIME_BROKER", "memory", func(c *Config) interface{} { return &c.Realtime.Broker }},
+	{"REALTIME_CHANNEL", "transaction_status_changes", func(c *Config) interface{} { return &c.Realtime.Channel }},
+	{"REALTIME_HEARTBEAT", "15s", func(c *Config) interface{} { return &c.Realtime.Heartbeat }},
+	{"REALTIME_BUFFER_SIZE", "16", func(c *Config) interface{} { return &c.Realtime.BufferSize }},
+
+	{"TRACING_EXPORTER", "none", func(c *Config) interface{} { return &c.Tracing.Exporter }},
+	{"TRACING_OTLP_ENDPOINT", "", func(c *Config) interface{} { return &c.Tracing.OTLPEndpoint }},
+	{"TRACING_SERVICE_NAME", "payment-gateway", func(c *Config) interface{} { return &c.Tracing.ServiceName }},
+	{"TRACING_SAMPLE_RATIO", "1", func(c *Config) interface{} { return &c.Tracing.SampleRatio }},
+
+	{"LOG_LEVEL", "info", func(c *Config) interface{} { return &c.Log.Level }},
+
+	{"FEATURE_OUTBOX_RELAY", "true", func(c *Config) interface{} { return &c.Features.OutboxRelay }},
+	{"FEATURE_EXPIRY_WORKER", "false", func(c *Config) interface{} { return &c.Features.ExpiryWorker }},
+	{"FEATURE_METRICS", "true", func(c *Config) interface{} { return &c.Features.Metrics }},
 }
@@ -0,0 +1,232 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// LoadConfig resolves every setting from, in increasing precedence: built-in
+// defaults, an optional YAML or TOML file (--config or CONFIG_FILE), an
+// optional .env file, environment variables and command-line flags. args are
+// the process arguments without the program name; the ones left after flag
+// parsing, such as a subcommand, are returned.
+//
+// A variable that is set but empty still overrides the file, so it can clear
+// a value such as a replica host. Settings that are not text fall back to
+// their default when cleared.
+//
+// All problems found are reported together in the returned error.
+func LoadConfig(args []string) (*Config, []string, error) {
+	flags := flag.NewFlagSet("payment-gateway", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	configFile := flags.String("config", "", "path to a YAML or TOML config file (env CONFIG_FILE)")
+	flagValues := make(map[string]*string, len(settings))
+	for _, s := range settings {
+		flagValues[s.key] = flags.String(flagName(s.key), "", "overrides "+s.key)
+	}
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			flags.SetOutput(os.Stderr)
+			flags.PrintDefaults()
+		}
+		return nil, nil, err
+	}
+
+	if err := godotenv.Load(); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, nil, fmt.Errorf("failed to load .env file: %w", err)
+	}
+
+	values := make(map[string]string, len(settings))
+	for _, s := range settings {
+		values[s.key] = s.def
+	}
+
+	var problems []error
+
+	path := *configFile
+	if path == "" {
+		path = os.Getenv("CONFIG_FILE")
+	}
+	if path != "" {
+		fileValues, err := readFile(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		for key, value := range fileValues {
+			if _, ok := values[key]; !ok {
+				problems = append(problems, fmt.Errorf("%s: unknown setting %s", path, strings.ToLower(key)))
+				continue
+			}
+			values[key] = value
+		}
+	}
+
+	for _, s := range settings {
+		if value, ok := os.LookupEnv(s.key); ok {
+			values[s.key] = value
+		}
+	}
+
+	flags.Visit(func(f *flag.Flag) {
+		for _, s := range settings {
+			if flagName(s.key) == f.Name {
+				values[s.key] = *flagValues[s.key]
+			}
+		}
+	})
+
+	cfg := &Config{}
+	for _, s := range settings {
+		value := values[s.key]
+		if value == "" && !isText(s.target(cfg)) {
+			value = s.def
+		}
+		if err := assign(s.target(cfg), value); err != nil {
+			problems = append(problems, fmt.Errorf("%s %v", s.key, err))
+			// Fall back to the default so validate does not report the
+			// same setting a second time.
+			assign(s.target(cfg), s.def)
+		}
+	}
+	if cfg.Outbox.WebhookSecret == "" {
+		cfg.Outbox.WebhookSecret = cfg.Security.SecretKey
+	}
+	if replica := &cfg.Database.Replica; replica.Host != "" {
+		if replica.Port == "" {
+			replica.Port = cfg.Database.Port
+		}
+		if replica.User == "" {
+			replica.User = cfg.Database.User
+		}
+		if replica.Password == "" {
+			replica.Password = cfg.Database.Password
+		}
+	}
+
+	problems = append(problems, cfg.validate()...)
+	if len(problems) > 0 {
+		return nil, nil, fmt.Errorf("invalid configuration:\n%w", errors.Join(problems...))
+	}
+	return cfg, flags.Args(), nil
+}
+
+func assign(target interface{}, value string) error {
+	var err error
+	switch t := target.(type) {
+	case *string:
+		*t = value
+	case *[]string:
+		*t = splitList(value)
+	case *int:
+		if *t, err = strconv.Atoi(value); err != nil {
+			return fmt.Errorf("must be an integer, got %q", value)
+		}
+	case *int64:
+		if *t, err = strconv.ParseInt(value, 10, 64); err != nil {
+			return fmt.Errorf("must be an integer, got %q", value)
+		}
+	case *float64:
+		if *t, err = strconv.ParseFloat(value, 64); err != nil {
+			return fmt.Errorf("must be a number, got %q", value)
+		}
+	case *bool:
+		if *t, err = strconv.ParseBool(value); err != nil {
+			return fmt.Errorf("must be true or false, got %q", value)
+		}
+	case *time.Duration:
+		if *t, err = time.ParseDuration(value); err != nil {
+			return fmt.Errorf("must be a duration such as 5s, got %q", value)
+		}
+	default:
+		return fmt.Errorf("has unsupported type %T", target)
+	}
+	return nil
+}
+
+func isText(target interface{}) bool {
+	switch target.(type) {
+	case *string, *[]string:
+		return true
+	}
+	return false
+}
+
+// readFile flattens a nested YAML or TOML document into setting keys, so
+// server.read_timeout in the file sets SERVER_READ_TIMEOUT.
+func readFile(path string) (map[string]string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var document map[string]interface{}
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(content, &document)
+	case ".toml":
+		err = toml.Unmarshal(content, &document)
+	default:
+		return nil, fmt.Errorf("unsupported config file extension %q, use .yaml, .yml or .toml", ext)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	values := make(map[string]string)
+	flatten("", document, values)
+	return values, nil
+}
+
+func flatten(prefix string, node map[string]interface{}, values map[string]string) {
+	keys := make([]string, 0, len(node))
+	for key := range node {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		name := strings.ToUpper(strings.ReplaceAll(key, "-", "_"))
+		if prefix != "" {
+			name = prefix + "_" + name
+		}
+		switch value := node[key].(type) {
+		case map[string]interface{}:
+			flatten(name, value, values)
+		case []interface{}:
+			items := make([]string, len(value))
+			for i, item := range value {
+				items[i] = fmt.Sprint(item)
+			}
+			values[name] = strings.Join(items, ",")
+		default:
+			values[name] = fmt.Sprint(value)
+		}
+	}
+}
+
+// flagName turns SERVER_READ_TIMEOUT into server-read-timeout.
+func flagName(key string) string {
+	return strings.ToLower(strings.ReplaceAll(key, "_", "-"))
+}
+
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
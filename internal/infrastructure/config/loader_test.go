@@ -0,0 +1,249 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// isolate clears every setting from the environment and moves into an empty
+// directory so a developer's .env or shell cannot leak into the test.
+func isolate(t *testing.T) string {
+	t.Helper()
+	for _, key := range append(settingKeys(), "CONFIG_FILE") {
+		t.Setenv(key, "")
+		os.Unsetenv(key)
+	}
+	dir := t.TempDir()
+	t.Chdir(dir)
+	return dir
+}
+
+func settingKeys() []string {
+	keys := make([]string, len(settings))
+	for i, s := range settings {
+		keys[i] = s.key
+	}
+	return keys
+}
+
+func writeFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write %s: %v", name, err)
+	}
+	return path
+}
+
+func TestLoadConfigPrecedence(t *testing.T) {
+	tests := []struct {
+		name   string
+		file   string
+		dotenv string
+		env    string
+		flag   string
+		want   string
+	}{
+		{name: "default", want: "8080"},
+		{name: "file over default", file: "8081", want: "8081"},
+		{name: ".env over file", file: "8081", dotenv: "8082", want: "8082"},
+		{name: "env over .env", file: "8081", dotenv: "8082", env: "8083", want: "8083"},
+		{name: "flag over env", file: "8081", dotenv: "8082", env: "8083", flag: "8084", want: "8084"},
+		{name: "flag over file", file: "8081", flag: "8084", want: "8084"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := isolate(t)
+			t.Setenv("DATABASE_PASSWORD", "database-password")
+			t.Setenv("SECRET_KEY", "secret-key")
+			t.Setenv("SECURITY_FEED_SECRET", "feed-secret")
+
+			var args []string
+			if tt.file != "" {
+				args = append(args, "--config", writeFile(t, dir, "gateway.yaml", "server:\n  port: "+tt.file+"\n"))
+			}
+			if tt.dotenv != "" {
+				writeFile(t, dir, ".env", "SERVER_PORT="+tt.dotenv+"\n")
+			}
+			if tt.env != "" {
+				t.Setenv("SERVER_PORT", tt.env)
+			}
+			if tt.flag != "" {
+				args = append(args, "--server-port", tt.flag)
+			}
+
+			cfg, _, err := LoadConfig(args)
+			if err != nil {
+				t.Fatalf("LoadConfig() error = %v", err)
+			}
+			if cfg.Server.Port != tt.want {
+				t.Errorf("Server.Port = %q, want %q", cfg.Server.Port, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadConfigFileFormats(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"gateway.yaml", `
+database:
+  password: database-password
+  replica:
+    host: replica.internal
+secret_key: secret-key
+security:
+  feed_secret: feed-secret
+server:
+  read-timeout: 20s
+  trusted_proxies: [10.0.0.0/8, 192.168.1.1]
+outbox:
+  batch_size: 50
+tracing:
+  sample_ratio: 0.25
+feature:
+  expiry_worker: true
+`},
+		{"gateway.toml", `
+secret_key = "secret-key"
+
+[security]
+feed_secret = "feed-secret"
+
+[database]
+password = "database-password"
+
+[database.replica]
+host = "replica.internal"
+
+[server]
+read-timeout = "20s"
+trusted_proxies = ["10.0.0.0/8", "192.168.1.1"]
+
+[outbox]
+batch_size = 50
+
+[tracing]
+sample_ratio = 0.25
+
+[feature]
+expiry_worker = true
+`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := isolate(t)
+			t.Setenv("CONFIG_FILE", writeFile(t, dir, tt.name, tt.content))
+
+			cfg, rest, err := LoadConfig([]string{"serve"})
+			if err != nil {
+				t.Fatalf("LoadConfig() error = %v", err)
+			}
+			if !reflect.DeepEqual(rest, []string{"serve"}) {
+				t.Errorf("remaining args = %v, want [serve]", rest)
+			}
+			if cfg.Database.Password != "database-password" || cfg.Security.SecretKey != "secret-key" {
+				t.Errorf("secrets were not read from the file: %+v", cfg.Security)
+			}
+			if cfg.Server.ReadTimeout != 20*time.Second {
+				t.Errorf("Server.ReadTimeout = %v, want 20s", cfg.Server.ReadTimeout)
+			}
+			if want := []string{"10.0.0.0/8", "192.168.1.1"}; !reflect.DeepEqual(cfg.Server.TrustedProxies, want) {
+				t.Errorf("Server.TrustedProxies = %v, want %v", cfg.Server.TrustedProxies, want)
+			}
+			if cfg.Outbox.BatchSize != 50 || cfg.Tracing.SampleRatio != 0.25 || !cfg.Features.ExpiryWorker {
+				t.Errorf("numbers and booleans were not read from the file: %+v %+v %+v", cfg.Outbox, cfg.Tracing, cfg.Features)
+			}
+			// Replica credentials default to the primary's.
+			if replica := cfg.Database.Replica; replica.Host != "replica.internal" || replica.Port != "5432" || replica.Password != "database-password" {
+				t.Errorf("Database.Replica = %+v", replica)
+			}
+		})
+	}
+}
+
+func TestLoadConfigEmptyEnvOverridesFile(t *testing.T) {
+	dir := isolate(t)
+	path := writeFile(t, dir, "gateway.yaml", `
+database:
+  password: database-password
+  replica:
+    host: replica.internal
+secret_key: secret-key
+security:
+  feed_secret: feed-secret
+server:
+  read_timeout: 20s
+`)
+	t.Setenv("DATABASE_REPLICA_HOST", "")
+	t.Setenv("SERVER_READ_TIMEOUT", "")
+
+	cfg, _, err := LoadConfig([]string{"--config", path})
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	if cfg.Database.Replica.Host != "" {
+		t.Errorf("Database.Replica.Host = %q, want it cleared by the empty variable", cfg.Database.Replica.Host)
+	}
+	if cfg.Server.ReadTimeout != 15*time.Second {
+		t.Errorf("Server.ReadTimeout = %v, want the 15s default once cleared", cfg.Server.ReadTimeout)
+	}
+}
+
+func TestLoadConfigReportsEveryProblem(t *testing.T) {
+	dir := isolate(t)
+	path := writeFile(t, dir, "gateway.yaml", "server:\n  colour: blue\n")
+	t.Setenv("SERVER_PORT", "http")
+	t.Setenv("SERVER_READ_TIMEOUT", "soon")
+	t.Setenv("OUTBOX_BATCH_SIZE", "many")
+
+	_, _, err := LoadConfig([]string{"--config", path})
+	if err == nil {
+		t.Fatal("LoadConfig() accepted an invalid configuration")
+	}
+	for _, want := range []string{
+		"unknown setting server_colour",
+		`SERVER_READ_TIMEOUT must be a duration such as 5s, got "soon"`,
+		`OUTBOX_BATCH_SIZE must be an integer, got "many"`,
+		`SERVER_PORT must be a port number, got "http"`,
+		"DATABASE_PASSWORD is required",
+		"SECRET_KEY is required",
+		"SECURITY_FEED_SECRET is required",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error lacks %q:\n%v", want, err)
+		}
+	}
+}
+
+func TestLoadConfigRejectsUnknownFileType(t *testing.T) {
+	dir := isolate(t)
+	path := writeFile(t, dir, "gateway.json", "{}")
+
+	if _, _, err := LoadConfig([]string{"--config", path}); err == nil || !strings.Contains(err.Error(), "unsupported config file extension") {
+		t.Errorf("LoadConfig() error = %v, want an unsupported extension error", err)
+	}
+}
+
+func TestExampleConfigLoads(t *testing.T) {
+	path, err := filepath.Abs("../../../config.example.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	isolate(t)
+	t.Setenv("DATABASE_PASSWORD", "database-password")
+	t.Setenv("SECRET_KEY", "secret-key")
+	t.Setenv("SECURITY_FEED_SECRET", "feed-secret")
+
+	if _, _, err := LoadConfig([]string{"--config", path}); err != nil {
+		t.Errorf("config.example.yaml does not load: %v", err)
+	}
+}
@@ -0,0 +1,134 @@
+package config
+
+import (
+	"fmt"
+	"log/slog"
+	"net"
+	"net/url"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// validate checks the loaded values for consistency and returns every problem
+// it finds rather than stopping at the first one.
+func (c *Config) validate() []error {
+	var problems []error
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			problems = append(problems, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(c.Database.Password != "", "DATABASE_PASSWORD is required")
+	check(c.Security.SecretKey != "", "SECRET_KEY is required")
+	check(c.Security.FeedSecret != "", "SECURITY_FEED_SECRET is required")
+	check(c.Security.FeedSecret == "" || c.Security.FeedSecret != c.Security.SecretKey,
+		"SECURITY_FEED_SECRET must differ from SECRET_KEY")
+	check(c.Security.SecretKeyNext == "" || c.Security.SecretKeyNext != c.Security.SecretKey,
+		"SECRET_KEY_NEXT must differ from SECRET_KEY")
+	check(c.Security.SecretKeyNext == "" || c.Security.SecretKeyNext != c.Security.FeedSecret,
+		"SECRET_KEY_NEXT must differ from SECURITY_FEED_SECRET")
+	check(validPort(c.Database.Port), "DATABASE_PORT must be a port number, got %q", c.Database.Port)
+	check(validPort(c.Server.Port), "SERVER_PORT must be a port number, got %q", c.Server.Port)
+	check(c.Database.MaxOpenConns >= 0, "DATABASE_MAX_OPEN_CONNS must not be negative")
+	check(c.Database.MaxIdleConns >= 0, "DATABASE_MAX_IDLE_CONNS must not be negative")
+	check(c.Database.MaxOpenConns == 0 || c.Database.MaxIdleConns <= c.Database.MaxOpenConns,
+		"DATABASE_MAX_IDLE_CONNS (%d) must not exceed DATABASE_MAX_OPEN_CONNS (%d)", c.Database.MaxIdleConns, c.Database.MaxOpenConns)
+
+	check(c.Database.ConnMaxLifetime >= 0, "DATABASE_CONN_MAX_LIFETIME must not be negative")
+	check(c.Database.ConnMaxIdleTime >= 0, "DATABASE_CONN_MAX_IDLE_TIME must not be negative")
+	check(c.Database.StatementTimeout >= 0, "DATABASE_STATEMENT_TIMEOUT must not be negative")
+	check(c.Database.Replica.Port == "" || validPort(c.Database.Replica.Port),
+		"DATABASE_REPLICA_PORT must be a port number, got %q", c.Database.Replica.Port)
+
+	for _, proxy := range c.Server.TrustedProxies {
+		check(validIPOrCIDR(proxy), "SERVER_TRUSTED_PROXIES entry %q is not an IP address or CIDR range", proxy)
+	}
+	check(c.Server.MaxHeaderBytes > 0, "SERVER_MAX_HEADER_BYTES must be positive")
+	check(c.Security.MaxBodyBytes > 0, "SECURITY_MAX_BODY_BYTES must be positive")
+
+	for _, origin := range c.CORS.AllowOrigins {
+		if origin == "*" {
+			check(!c.CORS.AllowCredentials, "CORS_ALLOW_ORIGINS cannot be * while CORS_ALLOW_CREDENTIALS is true")
+			continue
+		}
+		u, err := url.Parse(origin)
+		check(err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "" && u.Path == "",
+			"CORS_ALLOW_ORIGINS entry %q must be a scheme and host such as https://example.com", origin)
+	}
+
+	for _, d := range []struct {
+		key   string
+		value time.Duration
+	}{
+		{"SERVER_READ_TIMEOUT", c.Server.ReadTimeout},
+		{"SERVER_READ_HEADER_TIMEOUT", c.Server.ReadHeaderTimeout},
+		{"SERVER_WRITE_TIMEOUT", c.Server.WriteTimeout},
+		{"SERVER_IDLE_TIMEOUT", c.Server.IdleTimeout},
+		{"SERVER_SHUTDOWN_TIMEOUT", c.Server.ShutdownTimeout},
+		{"SERVER_HEALTH_TIMEOUT", c.Server.HealthTimeout},
+		{"OUTBOX_WEBHOOK_TIMEOUT", c.Outbox.WebhookTimeout},
+		{"OUTBOX_POLL_INTERVAL", c.Outbox.PollInterval},
+		{"OUTBOX_LEASE", c.Outbox.Lease},
+		{"EXPIRY_QR_TTL", c.Expiry.QRTTL},
+		{"EXPIRY_INTERVAL", c.Expiry.Interval},
+		{"TIMEOUT_GENERATE_QR", c.Timeouts.GenerateQR},
+		{"TIMEOUT_PROCESS_PAYMENT", c.Timeouts.ProcessPayment},
+		{"TIMEOUT_QUERY", c.Timeouts.Query},
+		{"TIMEOUT_EXPIRE_BATCH", c.Timeouts.ExpireBatch},
+		{"REALTIME_HEARTBEAT", c.Realtime.Heartbeat},
+		{"SECURITY_FEED_SIGNATURE_MAX_AGE", c.Security.FeedSignatureMaxAge},
+	} {
+		check(d.value > 0, "%s must be positive", d.key)
+	}
+	check(c.Server.ShutdownDelay >= 0, "SERVER_SHUTDOWN_DELAY must not be negative")
+	check(c.Payment.PaidTimeMaxSkew >= 0, "PAYMENT_PAID_TIME_MAX_SKEW must not be negative")
+
+	check(c.Outbox.BatchSize > 0, "OUTBOX_BATCH_SIZE must be positive")
+	check(c.Outbox.MaxAttempts > 0, "OUTBOX_MAX_ATTEMPTS must be positive")
+	check(c.Expiry.BatchSize > 0, "EXPIRY_BATCH_SIZE must be positive")
+	check(c.Realtime.BufferSize > 0, "REALTIME_BUFFER_SIZE must be positive")
+	check(c.Realtime.Broker == BrokerMemory || c.Realtime.Broker == BrokerPostgres,
+		"REALTIME_BROKER must be one of memory, postgres, got %q", c.Realtime.Broker)
+	check(channelPattern.MatchString(c.Realtime.Channel),
+		"REALTIME_CHANNEL must be 1-63 lowercase letters, digits or '_', starting with a letter, got %q", c.Realtime.Channel)
+	for _, sink := range c.Outbox.Sinks {
+		switch sink {
+		case "log", "bus":
+		case "webhook":
+			check(c.Outbox.WebhookURL != "", "OUTBOX_WEBHOOK_URL is required when the webhook sink is enabled")
+		default:
+			check(false, "OUTBOX_SINKS entry %q is not one of log, webhook, bus", sink)
+		}
+	}
+
+	switch c.Tracing.Exporter {
+	case "none", "stdout", "otlp":
+	default:
+		check(false, "TRACING_EXPORTER must be one of none, stdout, otlp, got %q", c.Tracing.Exporter)
+	}
+	check(c.Tracing.SampleRatio >= 0 && c.Tracing.SampleRatio <= 1, "TRACING_SAMPLE_RATIO must be between 0 and 1")
+
+	var level slog.Level
+	check(level.UnmarshalText([]byte(c.Log.Level)) == nil, "LOG_LEVEL must be one of debug, info, warn, error, got %q", c.Log.Level)
+
+	return problems
+}
+
+// channelPattern keeps the channel a plain Postgres identifier, so LISTEN
+// and pg_notify agree on its name without quoting rules coming into play.
+var channelPattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,62}$`)
+
+func validPort(port string) bool {
+	n, err := strconv.Atoi(port)
+	return err == nil && n > 0 && n <= 65535
+}
+
+func validIPOrCIDR(value string) bool {
+	if net.ParseIP(value) != nil {
+		return true
+	}
+	_, _, err := net.ParseCIDR(value)
+	return err == nil
+}
@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS transactions (
+    id                       BIGSERIAL PRIMARY KEY,
+    merchant_id              VARCHAR(50)    NOT NULL,
+    amount                   DECIMAL(15, 2) NOT NULL,
+    currency                 VARCHAR(3)     DEFAULT 'IDR',
+    trx_id                   VARCHAR(100),
+    partner_reference_number VARCHAR(100)   NOT NULL,
+    reference_number         VARCHAR(100)   NOT NULL,
+    status                   VARCHAR(20)    DEFAULT 'PENDING',
+    transaction_date         TIMESTAMPTZ    NOT NULL,
+    paid_date                TIMESTAMPTZ,
+    qr_content               TEXT,
+    created_at               TIMESTAMPTZ,
+    updated_at               TIMESTAMPTZ,
+    deleted_at               TIMESTAMPTZ
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_transactions_partner_reference_number ON transactions (partner_reference_number);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_transactions_reference_number ON transactions (reference_number);
+CREATE INDEX IF NOT EXISTS idx_transactions_deleted_at ON transactions (deleted_at);
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS outbox (
+    id           BIGSERIAL PRIMARY KEY,
+    aggregate_id VARCHAR(100) NOT NULL,
+    event_type   VARCHAR(50)  NOT NULL,
+    payload      TEXT         NOT NULL,
+    attempts     BIGINT       NOT NULL DEFAULT 0,
+    last_error   TEXT,
+    published_at TIMESTAMPTZ,
+    created_at   TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_outbox_aggregate_id ON outbox (aggregate_id);
+CREATE INDEX IF NOT EXISTS idx_outbox_published_at ON outbox (published_at);
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS transaction_events (
+    id               BIGSERIAL PRIMARY KEY,
+    transaction_id   BIGINT       NOT NULL,
+    reference_number VARCHAR(100) NOT NULL,
+    old_status       VARCHAR(20),
+    new_status       VARCHAR(20)  NOT NULL,
+    source           VARCHAR(30)  NOT NULL,
+    payload_hash     VARCHAR(64),
+    actor            VARCHAR(100),
+    created_at       TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_transaction_events_transaction_id ON transaction_events (transaction_id);
+CREATE INDEX IF NOT EXISTS idx_transaction_events_reference_number ON transaction_events (reference_number);
@@ -0,0 +1,2 @@
+ALTER TABLE transactions DROP COLUMN IF EXISTS paid_time_flag;
+ALTER TABLE transactions DROP COLUMN IF EXISTS received_at;
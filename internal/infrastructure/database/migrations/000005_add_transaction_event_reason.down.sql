@@ -0,0 +1 @@
+ALTER TABLE transaction_events DROP COLUMN IF EXISTS reason;
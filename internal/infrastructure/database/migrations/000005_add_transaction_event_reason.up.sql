@@ -0,0 +1 @@
+ALTER TABLE transaction_events ADD COLUMN IF NOT EXISTS reason VARCHAR(255);
@@ -0,0 +1,70 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"payment-gateway-manjo/backend/internal/domain/entity"
+	"payment-gateway-manjo/backend/internal/domain/repository"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type outboxRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewOutboxRepository(db *gorm.DB) repository.OutboxRepository {
+	return &outboxRepositoryImpl{db: db}
+}
+
+func (r *outboxRepositoryImpl) Add(ctx context.Context, event *entity.OutboxEvent) error {
+	return r.db.WithContext(ctx).Create(event).Error
+}
+
+// ClaimPending locks the candidate rows with SKIP LOCKED while it sets their
+// lease, so relays on several replicas never claim the same event. It must be
+// called inside a transaction, which can commit before publishing starts.
+func (r *outboxRepositoryImpl) ClaimPending(ctx context.Context, limit, maxAttempts int, until time.Time) ([]entity.OutboxEvent, error) {
+	var events []entity.OutboxEvent
+	err := r.db.WithContext(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+		Where("published_at IS NULL AND attempts < ?", maxAttempts).
+		Where("locked_until IS NULL OR locked_until <= ?", time.Now()).
+		Order("id ASC").
+		Limit(limit).
+		Find(&events).Error
+	if err != nil || len(events) == 0 {
+		return events, err
+	}
+
+	ids := make([]uint, len(events))
+	for i := range events {
+		ids[i] = events[i].ID
+		events[i].LockedUntil = &until
+	}
+	err = r.db.WithContext(ctx).Model(&entity.OutboxEvent{}).
+		Where("id IN ?", ids).
+		Update("locked_until", until).Error
+	return events, err
+}
+
+func (r *outboxRepositoryImpl) MarkPublished(ctx context.Context, id uint, publishedAt time.Time) error {
+	return r.db.WithContext(ctx).Model(&entity.OutboxEvent{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"published_at": publishedAt,
+			"last_error":   "",
+		}).Error
+}
+
+func (r *outboxRepositoryImpl) MarkFailed(ctx context.Context, id uint, reason string) error {
+	return r.db.WithContext(ctx).Model(&entity.OutboxEvent{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"attempts":     gorm.Expr("attempts + 1"),
+			"last_error":   reason,
+			"locked_until": nil,
+		}).Error
+}
@@ -2,39 +2,94 @@ package database
 
 import (
 	"fmt"
-	"log"
+	"log/slog"
+	"net"
+	"net/url"
+	"strconv"
 
-	"payment-gateway-manjo/backend/internal/domain/entity"
 	"payment-gateway-manjo/backend/internal/infrastructure/config"
+	"payment-gateway-manjo/backend/internal/infrastructure/logging"
 
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
-	"gorm.io/gorm/logger"
+	gormtracing "gorm.io/plugin/opentelemetry/tracing"
 )
 
+// NewPostgresDB connects to the primary database, which serves every write
+// and every read that must observe them.
 func NewPostgresDB(cfg *config.Config) (*gorm.DB, error) {
-	dsn := fmt.Sprintf(
-		"host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
-		cfg.Database.Host,
-		cfg.Database.Port,
-		cfg.Database.User,
-		cfg.Database.Password,	
-		cfg.Database.DBName,
-		cfg.Database.SSLMode,
-	)
-
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
-		Logger: logger.Default.LogMode(logger.Info),
-	})
-
+	db, err := open(cfg.Database, cfg.Database.Host, cfg.Database.Port, cfg.Database.User, cfg.Database.Password)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
 
-	if err := db.AutoMigrate(&entity.Transaction{}); err != nil {
-		return nil, fmt.Errorf("failed to migrate database: %w", err)
+	slog.Info("database connected", "host", cfg.Database.Host, "database", cfg.Database.DBName)
+	return db, nil
+}
+
+// NewReadReplica connects to the configured read replica, or returns primary
+// when none is configured so callers can use the result unconditionally.
+func NewReadReplica(cfg *config.Config, primary *gorm.DB) (*gorm.DB, error) {
+	replica := cfg.Database.Replica
+	if replica.Host == "" {
+		return primary, nil
+	}
+
+	db, err := open(cfg.Database, replica.Host, replica.Port, replica.User, replica.Password)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to read replica: %w", err)
+	}
+
+	slog.Info("read replica connected", "host", replica.Host, "database", cfg.Database.DBName)
+	return db, nil
+}
+
+func open(cfg config.DatabaseConfig, host, port, user, password string) (*gorm.DB, error) {
+	db, err := gorm.Open(postgres.Open(buildDSN(cfg, host, port, user, password)), &gorm.Config{
+		Logger:         logging.GormLogger(slog.Default()),
+		TranslateError: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := db.Use(gormtracing.NewPlugin(gormtracing.WithoutMetrics(), gormtracing.WithoutQueryVariables())); err != nil {
+		return nil, fmt.Errorf("failed to enable database tracing: %w", err)
 	}
 
-	log.Println("Database connected successfully")
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get database handle: %w", err)
+	}
+	sqlDB.SetMaxOpenConns(cfg.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	sqlDB.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
 	return db, nil
-}
\ No newline at end of file
+}
+
+// PrimaryDSN is the connection string of the primary database, for clients
+// that need a connection of their own, such as a LISTEN session.
+func PrimaryDSN(cfg *config.Config) string {
+	return buildDSN(cfg.Database, cfg.Database.Host, cfg.Database.Port, cfg.Database.User, cfg.Database.Password)
+}
+
+// buildDSN produces a postgres:// URL so credentials containing spaces, quotes
+// or other reserved characters are escaped rather than breaking the
+// key=value form. The statement timeout is sent as a session parameter.
+func buildDSN(cfg config.DatabaseConfig, host, port, user, password string) string {
+	query := url.Values{}
+	query.Set("sslmode", cfg.SSLMode)
+	if cfg.StatementTimeout > 0 {
+		query.Set("statement_timeout", strconv.FormatInt(cfg.StatementTimeout.Milliseconds(), 10))
+	}
+
+	dsn := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(user, password),
+		Host:     net.JoinHostPort(host, port),
+		Path:     "/" + cfg.DBName,
+		RawQuery: query.Encode(),
+	}
+	return dsn.String()
+}
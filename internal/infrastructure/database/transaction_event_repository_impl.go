@@ -0,0 +1,41 @@
+package database
+
+import (
+	"context"
+
+	"payment-gateway-manjo/backend/internal/domain/entity"
+	"payment-gateway-manjo/backend/internal/domain/repository"
+
+	"gorm.io/gorm"
+)
+
+type transactionEventRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewTransactionEventRepository(db *gorm.DB) repository.TransactionEventRepository {
+	return &transactionEventRepositoryImpl{db: db}
+}
+
+func (r *transactionEventRepositoryImpl) Create(ctx context.Context, event *entity.TransactionEvent) error {
+	return r.db.WithContext(ctx).Create(event).Error
+}
+
+func (r *transactionEventRepositoryImpl) FindByTransactionID(ctx context.Context, transactionID uint) ([]entity.TransactionEvent, error) {
+	var events []entity.TransactionEvent
+	err := r.db.WithContext(ctx).Where("transaction_id = ?", transactionID).Order("created_at ASC, id ASC").Find(&events).Error
+	return events, err
+}
+
+func (r *transactionEventRepositoryImpl) FindChangesByMerchant(ctx context.Context, merchantID string, afterID uint, limit int) ([]entity.StatusChange, error) {
+	var changes []entity.StatusChange
+	err := r.db.WithContext(ctx).Raw(`
+		SELECT e.id AS event_id, t.reference_number, t.partner_reference_number, t.merchant_id,
+		       e.old_status, e.new_status, t.amount, t.currency, e.source, e.created_at AS occurred_at
+		FROM transaction_events e
+		JOIN transactions t ON t.id = e.transaction_id
+		WHERE t.merchant_id = ? AND e.id > ?
+		ORDER BY e.id
+		LIMIT ?`, merchantID, afterID, limit).Scan(&changes).Error
+	return changes, err
+}
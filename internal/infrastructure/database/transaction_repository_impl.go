@@ -1,6 +1,11 @@
 package database
 
 import (
+	"context"
+	"errors"
+	"time"
+
+	"payment-gateway-manjo/backend/internal/domain/domainerr"
 	"payment-gateway-manjo/backend/internal/domain/entity"
 	"payment-gateway-manjo/backend/internal/domain/repository"
 
@@ -8,48 +13,74 @@ import (
 )
 
 type transactionRepositoryImpl struct {
-	db *gorm.DB
+	db     *gorm.DB
+	reader *gorm.DB
 }
 
-func NewTransactionRepository(db *gorm.DB) repository.TransactionRepository {
-	return &transactionRepositoryImpl{db: db}
+// NewTransactionRepository serves listing queries from reader, which may be a
+// read replica, and everything else from db. Pass the same handle twice when
+// there is no replica or inside a transaction.
+func NewTransactionRepository(db, reader *gorm.DB) repository.TransactionRepository {
+	return &transactionRepositoryImpl{db: db, reader: reader}
 }
 
-func (r *transactionRepositoryImpl) Create(transaction *entity.Transaction) error {
-	return r.db.Create(transaction).Error
+func (r *transactionRepositoryImpl) Create(ctx context.Context, transaction *entity.Transaction) error {
+	err := r.db.WithContext(ctx).Create(transaction).Error
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return domainerr.New(domainerr.ErrDuplicate, "partnerReferenceNo %s already exists", transaction.PartnerReferenceNumber)
+	}
+	return err
 }
 
-func (r *transactionRepositoryImpl) FindByReferenceNumber(referenceNumber string) (*entity.Transaction, error) {
+func (r *transactionRepositoryImpl) FindByReferenceNumber(ctx context.Context, referenceNumber string) (*entity.Transaction, error) {
 	var transaction entity.Transaction
-	err := r.db.Where("reference_number = ?", referenceNumber).First(&transaction).Error
+	err := r.db.WithContext(ctx).Where("reference_number = ?", referenceNumber).First(&transaction).Error
 	if err != nil {
-		return nil, err
+		return nil, translateNotFound(err)
 	}
 	return &transaction, nil
 }
 
-func (r *transactionRepositoryImpl) FindByPartnerReferenceNumber(partnerRefNo string) (*entity.Transaction, error) {
+func (r *transactionRepositoryImpl) FindByPartnerReferenceNumber(ctx context.Context, partnerRefNo string) (*entity.Transaction, error) {
 	var transaction entity.Transaction
-	err := r.db.Where("partner_reference_number = ?", partnerRefNo).First(&transaction).Error
+	err := r.db.WithContext(ctx).Where("partner_reference_number = ?", partnerRefNo).First(&transaction).Error
 	if err != nil {
-		return nil, err
+		return nil, translateNotFound(err)
 	}
 	return &transaction, nil
 }
 
-func (r *transactionRepositoryImpl) Update(transaction *entity.Transaction) error {
-	return r.db.Save(transaction).Error
+// Update writes every column of transaction, but only while the stored status
+// still equals expectedStatus. A concurrent change in between yields
+// domainerr.ErrConflict instead of silently overwriting it.
+func (r *transactionRepositoryImpl) Update(ctx context.Context, transaction *entity.Transaction, expectedStatus string) error {
+	result := r.db.WithContext(ctx).
+		Model(transaction).
+		Where("status = ?", expectedStatus).
+		Select("*").
+		Omit("id", "created_at").
+		Updates(transaction)
+	if errors.Is(result.Error, gorm.ErrDuplicatedKey) {
+		return domainerr.New(domainerr.ErrDuplicate, "partnerReferenceNo %s already exists", transaction.PartnerReferenceNumber)
+	}
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domainerr.New(domainerr.ErrConflict, "transaction %s was modified concurrently", transaction.ReferenceNumber)
+	}
+	return nil
 }
 
-func (r *transactionRepositoryImpl) FindAll() ([]entity.Transaction, error) {
+func (r *transactionRepositoryImpl) FindAll(ctx context.Context) ([]entity.Transaction, error) {
 	var transactions []entity.Transaction
-	err := r.db.Order("created_at DESC").Find(&transactions).Error
+	err := r.reader.WithContext(ctx).Order("created_at DESC").Find(&transactions).Error
 	return transactions, err
 }
 
-func (r *transactionRepositoryImpl) FindByFilters(merchantID, partnerRefNo, refNo, status string) ([]entity.Transaction, error) {
+func (r *transactionRepositoryImpl) FindByFilters(ctx context.Context, merchantID, partnerRefNo, refNo, status string) ([]entity.Transaction, error) {
 	var transactions []entity.Transaction
-	query := r.db.Model(&entity.Transaction{})
+	query := r.reader.WithContext(ctx).Model(&entity.Transaction{})
 
 	if merchantID != "" {
 		query = query.Where("merchant_id = ?", merchantID)
@@ -66,4 +97,20 @@ func (r *transactionRepositoryImpl) FindByFilters(merchantID, partnerRefNo, refN
 
 	err := query.Order("created_at DESC").Find(&transactions).Error
 	return transactions, err
-}
\ No newline at end of file
+}
+
+func (r *transactionRepositoryImpl) FindPendingBefore(ctx context.Context, cutoff time.Time, limit int) ([]entity.Transaction, error) {
+	var transactions []entity.Transaction
+	err := r.db.WithContext(ctx).Where("status = ? AND transaction_date < ?", entity.StatusPending, cutoff).
+		Order("transaction_date ASC").
+		Limit(limit).
+		Find(&transactions).Error
+	return transactions, err
+}
+
+func translateNotFound(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return domainerr.New(domainerr.ErrNotFound, "transaction not found")
+	}
+	return err
+}
@@ -0,0 +1,103 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+
+	"payment-gateway-manjo/backend/internal/domain/entity"
+	"payment-gateway-manjo/backend/internal/domain/pubsub"
+	"payment-gateway-manjo/backend/internal/domain/repository"
+
+	"gorm.io/gorm"
+)
+
+type transactorImpl struct {
+	db *gorm.DB
+	// Exactly one of these says where published status changes go.
+	statusChanges pubsub.Publisher
+	channel       string
+}
+
+// NewTransactor hands status changes published in a transaction to
+// statusChanges, an in-process broker, once it commits. statusChanges may be
+// nil when nothing in the process subscribes.
+func NewTransactor(db *gorm.DB, statusChanges pubsub.Publisher) repository.Transactor {
+	return &transactorImpl{db: db, statusChanges: statusChanges}
+}
+
+// NewNotifyingTransactor sends status changes published in a transaction with
+// pg_notify on channel. Postgres delivers them to every listener, in any
+// replica, when the transaction commits and drops them when it rolls back.
+func NewNotifyingTransactor(db *gorm.DB, channel string) repository.Transactor {
+	return &transactorImpl{db: db, channel: channel}
+}
+
+func (t *transactorImpl) WithinTransaction(ctx context.Context, fn func(repos repository.Repositories) error) error {
+	var pending []entity.StatusChange
+	err := t.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		repos := &gormRepositories{db: tx, statusChanges: &txStatusChanges{db: tx, channel: t.channel, pending: &pending}}
+		return fn(repos)
+	})
+	if err != nil || t.statusChanges == nil {
+		return err
+	}
+
+	for _, change := range pending {
+		if err := t.statusChanges.Publish(ctx, change); err != nil {
+			slog.WarnContext(ctx, "failed to publish status change", "reference_no", change.ReferenceNumber, "error", err)
+		}
+	}
+	return nil
+}
+
+type gormRepositories struct {
+	db            *gorm.DB
+	statusChanges *txStatusChanges
+}
+
+func (r *gormRepositories) Transactions() repository.TransactionRepository {
+	return NewTransactionRepository(r.db, r.db)
+}
+
+func (r *gormRepositories) Outbox() repository.OutboxRepository {
+	return NewOutboxRepository(r.db)
+}
+
+func (r *gormRepositories) TransactionEvents() repository.TransactionEventRepository {
+	return NewTransactionEventRepository(r.db)
+}
+
+func (r *gormRepositories) StatusChanges() pubsub.Publisher {
+	return r.statusChanges
+}
+
+// txStatusChanges notifies within the transaction when a channel is set and
+// otherwise keeps changes for delivery after commit.
+type txStatusChanges struct {
+	db      *gorm.DB
+	channel string
+	pending *[]entity.StatusChange
+}
+
+func (p *txStatusChanges) Publish(ctx context.Context, change entity.StatusChange) error {
+	if p.channel == "" {
+		*p.pending = append(*p.pending, change)
+		return nil
+	}
+	return Notify(ctx, p.db, p.channel, change)
+}
+
+// Notify sends change on channel as JSON. Run inside a transaction, it is
+// delivered on commit.
+func Notify(ctx context.Context, db *gorm.DB, channel string, change entity.StatusChange) error {
+	payload, err := json.Marshal(change)
+	if err != nil {
+		return fmt.Errorf("failed to encode status change: %w", err)
+	}
+	if err := db.WithContext(ctx).Exec("SELECT pg_notify(?, ?)", channel, string(payload)).Error; err != nil {
+		return fmt.Errorf("failed to notify status change: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	StatusUp   = "UP"
+	StatusDown = "DOWN"
+)
+
+type CheckFunc func(ctx context.Context) error
+
+type ComponentStatus struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+type check struct {
+	name string
+	fn   CheckFunc
+}
+
+// Probe aggregates the dependency checks behind the readiness endpoint.
+type Probe struct {
+	timeout      time.Duration
+	checks       []check
+	shuttingDown atomic.Bool
+}
+
+func NewProbe(timeout time.Duration) *Probe {
+	return &Probe{timeout: timeout}
+}
+
+// Register adds a named check. It must be called before the probe is served.
+func (p *Probe) Register(name string, fn CheckFunc) {
+	p.checks = append(p.checks, check{name: name, fn: fn})
+}
+
+// MarkShuttingDown makes every subsequent readiness check fail so that load
+// balancers stop routing traffic before the server stops accepting it.
+func (p *Probe) MarkShuttingDown() {
+	p.shuttingDown.Store(true)
+}
+
+// Ready runs all checks concurrently, each bounded by the probe timeout.
+func (p *Probe) Ready(ctx context.Context) (bool, map[string]ComponentStatus) {
+	components := make(map[string]ComponentStatus, len(p.checks)+1)
+	ready := true
+
+	if p.shuttingDown.Load() {
+		components["lifecycle"] = ComponentStatus{Status: StatusDown, Error: "shutting down"}
+		ready = false
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, c := range p.checks {
+		wg.Add(1)
+		go func(c check) {
+			defer wg.Done()
+
+			checkCtx, cancel := context.WithTimeout(ctx, p.timeout)
+			defer cancel()
+
+			status := ComponentStatus{Status: StatusUp}
+			if err := c.fn(checkCtx); err != nil {
+				status = ComponentStatus{Status: StatusDown, Error: err.Error()}
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			components[c.name] = status
+			if status.Status == StatusDown {
+				ready = false
+			}
+		}(c)
+	}
+	wg.Wait()
+
+	return ready, components
+}
+
+// Heartbeat lets a background worker report that its loop is still turning.
+// A nil Heartbeat ignores beats.
+type Heartbeat struct {
+	maxAge time.Duration
+	last   atomic.Int64
+}
+
+func NewHeartbeat(maxAge time.Duration) *Heartbeat {
+	h := &Heartbeat{maxAge: maxAge}
+	h.Beat()
+	return h
+}
+
+func (h *Heartbeat) Beat() {
+	if h == nil {
+		return
+	}
+	h.last.Store(time.Now().UnixNano())
+}
+
+func (h *Heartbeat) Check(ctx context.Context) error {
+	last := time.Unix(0, h.last.Load())
+	if age := time.Since(last); age > h.maxAge {
+		return errors.New("no heartbeat since " + last.Format(time.RFC3339))
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+package logging
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"reflect"
+	"strings"
+	"time"
+
+	"payment-gateway-manjo/backend/pkg/requestid"
+
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm/logger"
+)
+
+const redacted = "[REDACTED]"
+
+// sensitiveParts mark keys whose values never reach the log output: a key
+// containing any of them, case-insensitively, is redacted. Keys are checked
+// at every level, so SecretKey inside a logged config or qr_content inside a
+// logged transaction is caught too.
+var sensitiveParts = []string{
+	"signature",
+	"secret",
+	"password",
+	"authorization",
+	"qrcontent",
+	"qr_content",
+}
+
+// New returns a JSON logger writing to w at the given level. Every record
+// logged with a context carries that context's request and trace IDs.
+func New(w io.Writer, level string) (*slog.Logger, error) {
+	var lvl slog.Level
+	if err := lvl.UnmarshalText([]byte(level)); err != nil {
+		return nil, fmt.Errorf("invalid log level %q: %w", level, err)
+	}
+
+	handler := slog.NewJSONHandler(w, &slog.HandlerOptions{
+		Level:       lvl,
+		ReplaceAttr: redact,
+	})
+	return slog.New(&contextHandler{Handler: handler}), nil
+}
+
+// GormLogger logs SQL through logger without bound parameters. Statements are
+// only logged at debug level; errors and slow queries are always logged.
+func GormLogger(l *slog.Logger) logger.Interface {
+	level := logger.Warn
+	if l.Enabled(context.Background(), slog.LevelDebug) {
+		level = logger.Info
+	}
+	return logger.NewSlogLogger(l, logger.Config{
+		LogLevel:                  level,
+		SlowThreshold:             200 * time.Millisecond,
+		ParameterizedQueries:      true,
+		IgnoreRecordNotFoundError: true,
+	})
+}
+
+func redact(groups []string, attr slog.Attr) slog.Attr {
+	if sensitive(attr.Key) {
+		return slog.String(attr.Key, redacted)
+	}
+	if attr.Value.Kind() == slog.KindAny {
+		attr.Value = redactStructured(attr.Value.Any())
+	}
+	return attr
+}
+
+func sensitive(key string) bool {
+	key = strings.ToLower(key)
+	for _, part := range sensitiveParts {
+		if strings.Contains(key, part) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactStructured rewrites structs, maps and slices as their JSON form with
+// sensitive keys redacted. Anything that cannot be encoded is replaced by its
+// type name rather than risk printing its fields.
+func redactStructured(value interface{}) slog.Value {
+	switch value.(type) {
+	case error, time.Time, fmt.Stringer:
+		return slog.AnyValue(value)
+	}
+	switch reflect.Indirect(reflect.ValueOf(value)).Kind() {
+	case reflect.Struct, reflect.Map, reflect.Slice, reflect.Array:
+	default:
+		return slog.AnyValue(value)
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return slog.StringValue(fmt.Sprintf("[unloggable %T]", value))
+	}
+	var tree interface{}
+	if err := json.Unmarshal(data, &tree); err != nil {
+		return slog.StringValue(fmt.Sprintf("[unloggable %T]", value))
+	}
+	return slog.AnyValue(scrub(tree))
+}
+
+func scrub(node interface{}) interface{} {
+	switch n := node.(type) {
+	case map[string]interface{}:
+		for key, value := range n {
+			if sensitive(key) {
+				n[key] = redacted
+			} else {
+				n[key] = scrub(value)
+			}
+		}
+	case []interface{}:
+		for i := range n {
+			n[i] = scrub(n[i])
+		}
+	}
+	return node
+}
+
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h *contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if id := requestid.FromContext(ctx); id != "" {
+		record.AddAttrs(slog.String("request_id", id))
+	}
+	if spanCtx := trace.SpanContextFromContext(ctx); spanCtx.IsValid() {
+		record.AddAttrs(slog.String("trace_id", spanCtx.TraceID().String()))
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h *contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+func (h *contextHandler) WithGroup(name string) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithGroup(name)}
+}
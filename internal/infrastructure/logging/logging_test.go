@@ -0,0 +1,68 @@
+package logging
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"payment-gateway-manjo/backend/internal/domain/entity"
+	"payment-gateway-manjo/backend/internal/infrastructure/config"
+)
+
+func TestRedaction(t *testing.T) {
+	var out bytes.Buffer
+	logger, err := New(&out, "debug")
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	request := httptest.NewRequest("POST", "/api/v1/qr/payment", nil)
+	request.Header.Set("X-Signature", "header-signature-value")
+	request.Header.Set("Authorization", "Bearer authorization-value")
+
+	transaction := entity.Transaction{
+		ReferenceNumber: "A000000001",
+		QRContent:       "00020101021226qr-content-value",
+	}
+
+	cfg := config.Config{}
+	cfg.Security.SecretKey = "secret-key-value"
+	cfg.Database.Password = "database-password-value"
+	cfg.Database.Replica.Password = "replica-password-value"
+	cfg.Security.FeedSecret = "feed-secret-value"
+	cfg.Outbox.WebhookSecret = "webhook-secret-value"
+	cfg.Server.Port = "8080"
+
+	logger.Info("request", "signature", "attr-signature-value", "headers", request.Header)
+	logger.Info("entity", "transaction", transaction, "by_pointer", &transaction)
+	logger.Info("config", "config", cfg)
+	logger.Info("grouped", slog.Group("security", "secret_key", "grouped-secret-value"))
+	logger.Error("failed", "error", errors.New("visible error message"))
+
+	output := out.String()
+	for _, value := range []string{
+		"header-signature-value",
+		"authorization-value",
+		"attr-signature-value",
+		"qr-content-value",
+		"secret-key-value",
+		"database-password-value",
+		"replica-password-value",
+		"feed-secret-value",
+		"webhook-secret-value",
+		"grouped-secret-value",
+	} {
+		if strings.Contains(output, value) {
+			t.Errorf("log output contains %q:\n%s", value, output)
+		}
+	}
+	// Redaction must not swallow everything else.
+	for _, value := range []string{"A000000001", `"Port":"8080"`, "visible error message", redacted} {
+		if !strings.Contains(output, value) {
+			t.Errorf("log output lacks %q:\n%s", value, output)
+		}
+	}
+}
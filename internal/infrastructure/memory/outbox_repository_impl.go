@@ -0,0 +1,88 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"payment-gateway-manjo/backend/internal/domain/entity"
+	"payment-gateway-manjo/backend/internal/domain/repository"
+)
+
+type outboxRepositoryImpl struct {
+	store *Store
+}
+
+func NewOutboxRepository(store *Store) repository.OutboxRepository {
+	return &outboxRepositoryImpl{store: store}
+}
+
+func (r *outboxRepositoryImpl) Add(ctx context.Context, event *entity.OutboxEvent) error {
+	if err := r.store.lock(ctx); err != nil {
+		return err
+	}
+	defer r.store.mu.Unlock()
+
+	event.ID = r.store.nextID()
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now()
+	}
+	r.store.outbox = append(r.store.outbox, *event)
+	return nil
+}
+
+// ClaimPending leases unpublished events in insertion order. There is no row
+// locking; transactions on a Store are serialized instead.
+func (r *outboxRepositoryImpl) ClaimPending(ctx context.Context, limit, maxAttempts int, until time.Time) ([]entity.OutboxEvent, error) {
+	if err := r.store.lock(ctx); err != nil {
+		return nil, err
+	}
+	defer r.store.mu.Unlock()
+
+	now := time.Now()
+	var events []entity.OutboxEvent
+	for i := range r.store.outbox {
+		if len(events) == limit {
+			break
+		}
+		event := &r.store.outbox[i]
+		if event.PublishedAt != nil || event.Attempts >= maxAttempts {
+			continue
+		}
+		if event.LockedUntil != nil && event.LockedUntil.After(now) {
+			continue
+		}
+		lockedUntil := until
+		event.LockedUntil = &lockedUntil
+		events = append(events, *event)
+	}
+	return events, nil
+}
+
+func (r *outboxRepositoryImpl) MarkPublished(ctx context.Context, id uint, publishedAt time.Time) error {
+	return r.update(ctx, id, func(event *entity.OutboxEvent) {
+		event.PublishedAt = &publishedAt
+		event.LastError = ""
+	})
+}
+
+func (r *outboxRepositoryImpl) MarkFailed(ctx context.Context, id uint, reason string) error {
+	return r.update(ctx, id, func(event *entity.OutboxEvent) {
+		event.Attempts++
+		event.LastError = reason
+		event.LockedUntil = nil
+	})
+}
+
+func (r *outboxRepositoryImpl) update(ctx context.Context, id uint, apply func(event *entity.OutboxEvent)) error {
+	if err := r.store.lock(ctx); err != nil {
+		return err
+	}
+	defer r.store.mu.Unlock()
+
+	for i := range r.store.outbox {
+		if r.store.outbox[i].ID == id {
+			apply(&r.store.outbox[i])
+		}
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+// Package memory implements the repository interfaces on top of plain Go
+// slices. It mirrors the constraints of the Postgres schema, such as unique
+// reference numbers, so usecases can be exercised without a database.
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"payment-gateway-manjo/backend/internal/domain/entity"
+)
+
+// Store holds every table. Repositories created from the same Store see the
+// same data.
+type Store struct {
+	mu sync.Mutex
+	// txMu serializes WithinTransaction calls so a rollback never discards
+	// writes made by another transaction.
+	txMu sync.Mutex
+
+	transactions      []entity.Transaction
+	transactionEvents []entity.TransactionEvent
+	outbox            []entity.OutboxEvent
+	lastID            uint
+}
+
+func NewStore() *Store {
+	return &Store{}
+}
+
+type snapshot struct {
+	transactions      []entity.Transaction
+	transactionEvents []entity.TransactionEvent
+	outbox            []entity.OutboxEvent
+	lastID            uint
+}
+
+func (s *Store) snapshot() snapshot {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return snapshot{
+		transactions:      append([]entity.Transaction(nil), s.transactions...),
+		transactionEvents: append([]entity.TransactionEvent(nil), s.transactionEvents...),
+		outbox:            append([]entity.OutboxEvent(nil), s.outbox...),
+		lastID:            s.lastID,
+	}
+}
+
+func (s *Store) restore(snap snapshot) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.transactions = snap.transactions
+	s.transactionEvents = snap.transactionEvents
+	s.outbox = snap.outbox
+	s.lastID = snap.lastID
+}
+
+// nextID hands out identifiers shared across tables, which keeps them unique
+// and increasing like a database sequence. Callers must hold mu.
+func (s *Store) nextID() uint {
+	s.lastID++
+	return s.lastID
+}
+
+// lock acquires the store after checking ctx, so cancelled or timed out
+// operations fail the same way they would against a database.
+func (s *Store) lock(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	return nil
+}
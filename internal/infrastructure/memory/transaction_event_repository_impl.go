@@ -0,0 +1,77 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"payment-gateway-manjo/backend/internal/domain/entity"
+	"payment-gateway-manjo/backend/internal/domain/repository"
+)
+
+type transactionEventRepositoryImpl struct {
+	store *Store
+}
+
+func NewTransactionEventRepository(store *Store) repository.TransactionEventRepository {
+	return &transactionEventRepositoryImpl{store: store}
+}
+
+func (r *transactionEventRepositoryImpl) Create(ctx context.Context, event *entity.TransactionEvent) error {
+	if err := r.store.lock(ctx); err != nil {
+		return err
+	}
+	defer r.store.mu.Unlock()
+
+	event.ID = r.store.nextID()
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now()
+	}
+	r.store.transactionEvents = append(r.store.transactionEvents, *event)
+	return nil
+}
+
+func (r *transactionEventRepositoryImpl) FindByTransactionID(ctx context.Context, transactionID uint) ([]entity.TransactionEvent, error) {
+	if err := r.store.lock(ctx); err != nil {
+		return nil, err
+	}
+	defer r.store.mu.Unlock()
+
+	// Events are appended in creation order, which is the order the
+	// database implementation sorts by.
+	var events []entity.TransactionEvent
+	for _, event := range r.store.transactionEvents {
+		if event.TransactionID == transactionID {
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
+
+func (r *transactionEventRepositoryImpl) FindChangesByMerchant(ctx context.Context, merchantID string, afterID uint, limit int) ([]entity.StatusChange, error) {
+	if err := r.store.lock(ctx); err != nil {
+		return nil, err
+	}
+	defer r.store.mu.Unlock()
+
+	transactions := make(map[uint]*entity.Transaction)
+	for i := range r.store.transactions {
+		if r.store.transactions[i].MerchantID == merchantID {
+			transactions[r.store.transactions[i].ID] = &r.store.transactions[i]
+		}
+	}
+
+	// IDs come from one counter, so append order is ID order.
+	var changes []entity.StatusChange
+	for i := range r.store.transactionEvents {
+		event := &r.store.transactionEvents[i]
+		transaction, ok := transactions[event.TransactionID]
+		if !ok || event.ID <= afterID {
+			continue
+		}
+		if len(changes) == limit {
+			break
+		}
+		changes = append(changes, entity.NewStatusChange(transaction, event))
+	}
+	return changes, nil
+}
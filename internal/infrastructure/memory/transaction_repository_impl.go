@@ -0,0 +1,173 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"payment-gateway-manjo/backend/internal/domain/domainerr"
+	"payment-gateway-manjo/backend/internal/domain/entity"
+	"payment-gateway-manjo/backend/internal/domain/repository"
+)
+
+type transactionRepositoryImpl struct {
+	store *Store
+}
+
+func NewTransactionRepository(store *Store) repository.TransactionRepository {
+	return &transactionRepositoryImpl{store: store}
+}
+
+func (r *transactionRepositoryImpl) Create(ctx context.Context, transaction *entity.Transaction) error {
+	if err := r.store.lock(ctx); err != nil {
+		return err
+	}
+	defer r.store.mu.Unlock()
+
+	for _, existing := range r.store.transactions {
+		if existing.PartnerReferenceNumber == transaction.PartnerReferenceNumber ||
+			existing.ReferenceNumber == transaction.ReferenceNumber {
+			return domainerr.New(domainerr.ErrDuplicate, "partnerReferenceNo %s already exists", transaction.PartnerReferenceNumber)
+		}
+	}
+
+	now := time.Now()
+	transaction.ID = r.store.nextID()
+	if transaction.CreatedAt.IsZero() {
+		transaction.CreatedAt = now
+	}
+	transaction.UpdatedAt = now
+	if transaction.Status == "" {
+		transaction.Status = entity.StatusPending
+	}
+	if transaction.Currency == "" {
+		transaction.Currency = "IDR"
+	}
+	r.store.transactions = append(r.store.transactions, cloneTransaction(*transaction))
+	return nil
+}
+
+func (r *transactionRepositoryImpl) FindByReferenceNumber(ctx context.Context, referenceNumber string) (*entity.Transaction, error) {
+	return r.findOne(ctx, func(t *entity.Transaction) bool {
+		return t.ReferenceNumber == referenceNumber
+	})
+}
+
+func (r *transactionRepositoryImpl) FindByPartnerReferenceNumber(ctx context.Context, partnerRefNo string) (*entity.Transaction, error) {
+	return r.findOne(ctx, func(t *entity.Transaction) bool {
+		return t.PartnerReferenceNumber == partnerRefNo
+	})
+}
+
+// Update follows the database implementation: every field but ID and
+// CreatedAt is overwritten, and only while the stored status still equals
+// expectedStatus.
+func (r *transactionRepositoryImpl) Update(ctx context.Context, transaction *entity.Transaction, expectedStatus string) error {
+	if err := r.store.lock(ctx); err != nil {
+		return err
+	}
+	defer r.store.mu.Unlock()
+
+	for i := range r.store.transactions {
+		stored := &r.store.transactions[i]
+		if stored.ID != transaction.ID || stored.Status != expectedStatus {
+			continue
+		}
+		for j := range r.store.transactions {
+			other := r.store.transactions[j]
+			if j != i && (other.PartnerReferenceNumber == transaction.PartnerReferenceNumber ||
+				other.ReferenceNumber == transaction.ReferenceNumber) {
+				return domainerr.New(domainerr.ErrDuplicate, "partnerReferenceNo %s already exists", transaction.PartnerReferenceNumber)
+			}
+		}
+
+		transaction.CreatedAt = stored.CreatedAt
+		transaction.UpdatedAt = time.Now()
+		*stored = cloneTransaction(*transaction)
+		return nil
+	}
+	return domainerr.New(domainerr.ErrConflict, "transaction %s was modified concurrently", transaction.ReferenceNumber)
+}
+
+func (r *transactionRepositoryImpl) FindAll(ctx context.Context) ([]entity.Transaction, error) {
+	return r.FindByFilters(ctx, "", "", "", "")
+}
+
+func (r *transactionRepositoryImpl) FindByFilters(ctx context.Context, merchantID, partnerRefNo, refNo, status string) ([]entity.Transaction, error) {
+	transactions, err := r.filter(ctx, func(t *entity.Transaction) bool {
+		return (merchantID == "" || t.MerchantID == merchantID) &&
+			(partnerRefNo == "" || t.PartnerReferenceNumber == partnerRefNo) &&
+			(refNo == "" || t.ReferenceNumber == refNo) &&
+			(status == "" || t.Status == status)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(transactions, func(i, j int) bool {
+		if transactions[i].CreatedAt.Equal(transactions[j].CreatedAt) {
+			return transactions[i].ID > transactions[j].ID
+		}
+		return transactions[i].CreatedAt.After(transactions[j].CreatedAt)
+	})
+	return transactions, nil
+}
+
+func (r *transactionRepositoryImpl) FindPendingBefore(ctx context.Context, cutoff time.Time, limit int) ([]entity.Transaction, error) {
+	transactions, err := r.filter(ctx, func(t *entity.Transaction) bool {
+		return t.Status == entity.StatusPending && t.TransactionDate.Before(cutoff)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(transactions, func(i, j int) bool {
+		return transactions[i].TransactionDate.Before(transactions[j].TransactionDate)
+	})
+	if limit >= 0 && len(transactions) > limit {
+		transactions = transactions[:limit]
+	}
+	return transactions, nil
+}
+
+func (r *transactionRepositoryImpl) findOne(ctx context.Context, match func(t *entity.Transaction) bool) (*entity.Transaction, error) {
+	transactions, err := r.filter(ctx, match)
+	if err != nil {
+		return nil, err
+	}
+	if len(transactions) == 0 {
+		return nil, domainerr.New(domainerr.ErrNotFound, "transaction not found")
+	}
+	return &transactions[0], nil
+}
+
+func (r *transactionRepositoryImpl) filter(ctx context.Context, match func(t *entity.Transaction) bool) ([]entity.Transaction, error) {
+	if err := r.store.lock(ctx); err != nil {
+		return nil, err
+	}
+	defer r.store.mu.Unlock()
+
+	var transactions []entity.Transaction
+	for i := range r.store.transactions {
+		if match(&r.store.transactions[i]) {
+			transactions = append(transactions, cloneTransaction(r.store.transactions[i]))
+		}
+	}
+	return transactions, nil
+}
+
+// cloneTransaction copies the time pointers too, so callers mutating what
+// they got back cannot change the stored row.
+func cloneTransaction(t entity.Transaction) entity.Transaction {
+	t.PaidDate = cloneTime(t.PaidDate)
+	t.ReceivedAt = cloneTime(t.ReceivedAt)
+	return t
+}
+
+func cloneTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	copied := *t
+	return &copied
+}
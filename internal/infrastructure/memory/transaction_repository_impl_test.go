@@ -0,0 +1,14 @@
+package memory
+
+import (
+	"testing"
+
+	"payment-gateway-manjo/backend/internal/domain/repository"
+	"payment-gateway-manjo/backend/internal/domain/repository/repositorytest"
+)
+
+func TestTransactionRepositoryConformance(t *testing.T) {
+	repositorytest.RunTransactionRepository(t, func(t *testing.T) repository.TransactionRepository {
+		return NewTransactionRepository(NewStore())
+	})
+}
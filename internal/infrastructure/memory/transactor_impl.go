@@ -0,0 +1,80 @@
+package memory
+
+import (
+	"context"
+	"log/slog"
+
+	"payment-gateway-manjo/backend/internal/domain/entity"
+	"payment-gateway-manjo/backend/internal/domain/pubsub"
+	"payment-gateway-manjo/backend/internal/domain/repository"
+)
+
+type transactorImpl struct {
+	store         *Store
+	statusChanges pubsub.Publisher
+}
+
+// NewTransactor hands status changes published in a transaction to
+// statusChanges once it succeeds. statusChanges may be nil when nothing
+// subscribes.
+func NewTransactor(store *Store, statusChanges pubsub.Publisher) repository.Transactor {
+	return &transactorImpl{store: store, statusChanges: statusChanges}
+}
+
+// WithinTransaction restores the store to its previous state when fn fails,
+// giving the same all-or-nothing outcome as a database transaction.
+func (t *transactorImpl) WithinTransaction(ctx context.Context, fn func(repos repository.Repositories) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	t.store.txMu.Lock()
+	defer t.store.txMu.Unlock()
+
+	snap := t.store.snapshot()
+	repos := &memoryRepositories{store: t.store}
+	if err := fn(repos); err != nil {
+		t.store.restore(snap)
+		return err
+	}
+
+	if t.statusChanges != nil {
+		for _, change := range repos.statusChanges.pending {
+			if err := t.statusChanges.Publish(ctx, change); err != nil {
+				slog.WarnContext(ctx, "failed to publish status change", "reference_no", change.ReferenceNumber, "error", err)
+			}
+		}
+	}
+	return nil
+}
+
+type memoryRepositories struct {
+	store         *Store
+	statusChanges pendingStatusChanges
+}
+
+func (r *memoryRepositories) Transactions() repository.TransactionRepository {
+	return NewTransactionRepository(r.store)
+}
+
+func (r *memoryRepositories) Outbox() repository.OutboxRepository {
+	return NewOutboxRepository(r.store)
+}
+
+func (r *memoryRepositories) TransactionEvents() repository.TransactionEventRepository {
+	return NewTransactionEventRepository(r.store)
+}
+
+func (r *memoryRepositories) StatusChanges() pubsub.Publisher {
+	return &r.statusChanges
+}
+
+// pendingStatusChanges holds changes until the transaction is known to have
+// succeeded.
+type pendingStatusChanges struct {
+	pending []entity.StatusChange
+}
+
+func (p *pendingStatusChanges) Publish(ctx context.Context, change entity.StatusChange) error {
+	p.pending = append(p.pending, change)
+	return nil
+}
@@ -0,0 +1,54 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"payment-gateway-manjo/backend/internal/domain/entity"
+	"payment-gateway-manjo/backend/internal/domain/repository"
+)
+
+type recordingPublisher struct {
+	changes []entity.StatusChange
+}
+
+func (p *recordingPublisher) Publish(ctx context.Context, change entity.StatusChange) error {
+	p.changes = append(p.changes, change)
+	return nil
+}
+
+func TestTransactorPublishesOnlyCommittedChanges(t *testing.T) {
+	ctx := context.Background()
+	publisher := &recordingPublisher{}
+	transactor := NewTransactor(NewStore(), publisher)
+
+	rollback := errors.New("rollback")
+	err := transactor.WithinTransaction(ctx, func(repos repository.Repositories) error {
+		if err := repos.StatusChanges().Publish(ctx, entity.StatusChange{EventID: 1}); err != nil {
+			t.Fatalf("Publish() error = %v", err)
+		}
+		return rollback
+	})
+	if !errors.Is(err, rollback) {
+		t.Fatalf("WithinTransaction() error = %v, want rollback", err)
+	}
+	if len(publisher.changes) != 0 {
+		t.Fatalf("rolled back transaction published %v", publisher.changes)
+	}
+
+	err = transactor.WithinTransaction(ctx, func(repos repository.Repositories) error {
+		repos.StatusChanges().Publish(ctx, entity.StatusChange{EventID: 2})
+		repos.StatusChanges().Publish(ctx, entity.StatusChange{EventID: 3})
+		if len(publisher.changes) != 0 {
+			t.Error("changes were published before the transaction finished")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("WithinTransaction() error = %v", err)
+	}
+	if len(publisher.changes) != 2 || publisher.changes[0].EventID != 2 || publisher.changes[1].EventID != 3 {
+		t.Errorf("published %v, want events 2 and 3 in order", publisher.changes)
+	}
+}
@@ -0,0 +1,153 @@
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "payment_gateway"
+
+// Registry holds every collector exposed on /metrics. A dedicated registry is
+// used instead of the global one so wiring the app twice, as tests do, does
+// not panic on duplicate registration of the database collector.
+var Registry = prometheus.NewRegistry()
+
+var (
+	HTTPRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests by method, route and status code.",
+	}, []string{"method", "route", "status"})
+
+	HTTPRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency by method, route and status code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	QRGenerated = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "qr_generated_total",
+		Help:      "QR codes generated by currency.",
+	}, []string{"currency"})
+
+	Payments = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "payments_total",
+		Help:      "Payment notifications that changed a transaction, by the status set; retries of a final status are not counted.",
+	}, []string{"status"})
+
+	PaymentAmount = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "payment_amount_total",
+		Help:      "Sum of successfully paid amounts by currency.",
+	}, []string{"currency"})
+
+	SignatureFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "signature_failures_total",
+		Help:      "Rejected signed requests by reason.",
+	}, []string{"reason"})
+
+	LegacySignatures = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "legacy_signatures_total",
+		Help:      "Signed requests accepted with the field-based signature instead of a body signature.",
+	})
+
+	NextKeySignatures = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "next_key_signatures_total",
+		Help:      "Signed requests accepted with SECRET_KEY_NEXT during a key rotation.",
+	})
+
+	AmountMismatches = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "amount_mismatches_total",
+		Help:      "Payment notifications whose amount differs from the transaction.",
+	})
+
+	PaidTimeAnomalies = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "paid_time_anomalies_total",
+		Help:      "Accepted payment notifications whose paid time was flagged, by flag.",
+	}, []string{"flag"})
+
+	ExpiryRuns = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "expiry_runs_total",
+		Help:      "Expiry job runs by result.",
+	}, []string{"result"})
+
+	TransactionsExpired = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "transactions_expired_total",
+		Help:      "Transactions moved to EXPIRED by the expiry job.",
+	})
+
+	RealtimeConnections = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "realtime_connections",
+		Help:      "Open real-time status streams by transport.",
+	}, []string{"transport"})
+
+	RealtimeDropped = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "realtime_dropped_total",
+		Help:      "Real-time status streams closed because the client fell behind, by transport.",
+	}, []string{"transport"})
+
+	RealtimeListenerReconnects = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "realtime_listener_reconnects_total",
+		Help:      "Times the LISTEN connection for status changes was re-established after failing.",
+	})
+)
+
+const (
+	SignatureMissing   = "missing"
+	SignatureInvalid   = "invalid"
+	SignatureMalformed = "malformed_body"
+	SignatureExpired   = "expired"
+)
+
+const (
+	TransportSSE       = "sse"
+	TransportWebSocket = "websocket"
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		HTTPRequests,
+		HTTPRequestDuration,
+		QRGenerated,
+		Payments,
+		PaymentAmount,
+		SignatureFailures,
+		LegacySignatures,
+		NextKeySignatures,
+		AmountMismatches,
+		PaidTimeAnomalies,
+		ExpiryRuns,
+		TransactionsExpired,
+		RealtimeConnections,
+		RealtimeDropped,
+		RealtimeListenerReconnects,
+	)
+}
+
+// RegisterDB exposes connection pool gauges from sql.DB.Stats().
+func RegisterDB(db *sql.DB, name string) error {
+	return Registry.Register(collectors.NewDBStatsCollector(db, name))
+}
+
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+}
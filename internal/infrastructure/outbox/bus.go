@@ -0,0 +1,54 @@
+package outbox
+
+import (
+	"context"
+	"sync"
+
+	"payment-gateway-manjo/backend/internal/domain/entity"
+)
+
+type Handler func(ctx context.Context, event entity.OutboxEvent)
+
+// Bus is an in-process sink that fans events out to subscribers in the same
+// binary. Handlers run synchronously on the relay goroutine and must not block.
+type Bus struct {
+	mu       sync.RWMutex
+	nextID   int
+	handlers map[int]Handler
+}
+
+func NewBus() *Bus {
+	return &Bus{
+		handlers: make(map[int]Handler),
+	}
+}
+
+func (b *Bus) Name() string {
+	return "bus"
+}
+
+// Subscribe registers handler and returns a function that removes it.
+func (b *Bus) Subscribe(handler Handler) func() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	id := b.nextID
+	b.nextID++
+	b.handlers[id] = handler
+
+	return func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		delete(b.handlers, id)
+	}
+}
+
+func (b *Bus) Publish(ctx context.Context, event entity.OutboxEvent) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for _, handler := range b.handlers {
+		handler(ctx, event)
+	}
+	return nil
+}
@@ -0,0 +1,27 @@
+package outbox
+
+import (
+	"context"
+	"log/slog"
+
+	"payment-gateway-manjo/backend/internal/domain/entity"
+)
+
+type LogSink struct{}
+
+func NewLogSink() *LogSink {
+	return &LogSink{}
+}
+
+func (s *LogSink) Name() string {
+	return "log"
+}
+
+func (s *LogSink) Publish(ctx context.Context, event entity.OutboxEvent) error {
+	slog.InfoContext(ctx, "outbox event",
+		"event_id", event.ID,
+		"event_type", event.EventType,
+		"aggregate_id", event.AggregateID,
+	)
+	return nil
+}
@@ -0,0 +1,144 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"payment-gateway-manjo/backend/internal/domain/entity"
+	"payment-gateway-manjo/backend/internal/domain/pubsub"
+	"payment-gateway-manjo/backend/internal/domain/repository"
+	"payment-gateway-manjo/backend/internal/infrastructure/health"
+)
+
+// Sink receives outbox events once they have been committed to the database.
+// Delivery is at-least-once, so sinks must tolerate duplicates.
+type Sink interface {
+	Name() string
+	Publish(ctx context.Context, event entity.OutboxEvent) error
+}
+
+type RelayConfig struct {
+	PollInterval time.Duration
+	BatchSize    int
+	MaxAttempts  int
+	// Lease is how long a claimed batch is reserved for this relay. Events
+	// still unpublished when it runs out are left for the next claim.
+	Lease time.Duration
+}
+
+type Relay struct {
+	transactor repository.Transactor
+	sinks      []Sink
+	cfg        RelayConfig
+	wakeups    pubsub.Subscriber
+	heartbeat  *health.Heartbeat
+}
+
+// NewRelay polls on cfg.PollInterval and, in between, whenever wakeups
+// announces a status change, since every change may have added an event.
+func NewRelay(transactor repository.Transactor, sinks []Sink, cfg RelayConfig, wakeups pubsub.Subscriber, heartbeat *health.Heartbeat) *Relay {
+	return &Relay{
+		transactor: transactor,
+		sinks:      sinks,
+		cfg:        cfg,
+		wakeups:    wakeups,
+		heartbeat:  heartbeat,
+	}
+}
+
+// Run polls the outbox until ctx is cancelled. A batch that is already being
+// published when ctx is cancelled is allowed to finish.
+func (r *Relay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.cfg.PollInterval)
+	defer ticker.Stop()
+
+	wakeups := r.wakeups.Subscribe(pubsub.Filter{})
+	defer func() { wakeups.Close() }()
+
+	for {
+		if err := r.dispatch(context.WithoutCancel(ctx)); err != nil {
+			slog.ErrorContext(ctx, "outbox relay dispatch failed", "error", err)
+		}
+		r.heartbeat.Beat()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case _, ok := <-wakeups.Events():
+			if !ok {
+				// Dropped or interrupted; dispatching now covers anything
+				// missed in the meantime.
+				wakeups = r.wakeups.Subscribe(pubsub.Filter{})
+			}
+			drain(wakeups)
+		}
+	}
+}
+
+// drain discards wake-ups that are already queued: one dispatch covers all of
+// them.
+func drain(subscription pubsub.Subscription) {
+	for {
+		select {
+		case _, ok := <-subscription.Events():
+			if !ok {
+				return
+			}
+		default:
+			return
+		}
+	}
+}
+
+// dispatch claims a batch in one short transaction and publishes it outside
+// any, so slow sinks hold neither a connection nor row locks. Each result is
+// then recorded on its own, and a failure to record one does not undo others.
+func (r *Relay) dispatch(ctx context.Context) error {
+	until := time.Now().Add(r.cfg.Lease)
+
+	var events []entity.OutboxEvent
+	err := r.transactor.WithinTransaction(ctx, func(repos repository.Repositories) error {
+		var err error
+		events, err = repos.Outbox().ClaimPending(ctx, r.cfg.BatchSize, r.cfg.MaxAttempts, until)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to claim pending events: %w", err)
+	}
+
+	var errs []error
+	for _, event := range events {
+		if time.Now().After(until) {
+			// Another relay may have claimed the rest by now.
+			break
+		}
+		// A batch can take minutes against a slow sink; beating per event
+		// keeps readiness from reporting a working relay as stuck.
+		r.heartbeat.Beat()
+		publishErr := r.publish(ctx, event)
+		err := r.transactor.WithinTransaction(ctx, func(repos repository.Repositories) error {
+			if publishErr != nil {
+				return repos.Outbox().MarkFailed(ctx, event.ID, publishErr.Error())
+			}
+			return repos.Outbox().MarkPublished(ctx, event.ID, time.Now())
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to record the result of event %d: %w", event.ID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (r *Relay) publish(ctx context.Context, event entity.OutboxEvent) error {
+	var errs []error
+	for _, sink := range r.sinks {
+		if err := sink.Publish(ctx, event); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", sink.Name(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
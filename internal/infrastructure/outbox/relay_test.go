@@ -0,0 +1,214 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"payment-gateway-manjo/backend/internal/domain/entity"
+	"payment-gateway-manjo/backend/internal/infrastructure/broker"
+	"payment-gateway-manjo/backend/internal/infrastructure/health"
+	"payment-gateway-manjo/backend/internal/infrastructure/memory"
+)
+
+// fakeSink records what it was given, optionally after a delay or failing.
+type fakeSink struct {
+	delay time.Duration
+	err   error
+
+	mu        sync.Mutex
+	published []uint
+}
+
+func (s *fakeSink) Name() string {
+	return "fake"
+}
+
+func (s *fakeSink) Publish(ctx context.Context, event entity.OutboxEvent) error {
+	time.Sleep(s.delay)
+	if s.err != nil {
+		return s.err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.published = append(s.published, event.ID)
+	return nil
+}
+
+func (s *fakeSink) ids() []uint {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]uint(nil), s.published...)
+}
+
+func seedOutbox(t *testing.T, store *memory.Store, n int) {
+	t.Helper()
+	outbox := memory.NewOutboxRepository(store)
+	for i := 0; i < n; i++ {
+		event, err := entity.NewOutboxEvent(entity.EventTransactionCreated, "R-1", map[string]int{"n": i})
+		if err != nil {
+			t.Fatalf("failed to build event: %v", err)
+		}
+		if err := outbox.Add(context.Background(), event); err != nil {
+			t.Fatalf("failed to seed event: %v", err)
+		}
+	}
+}
+
+// pending lists unpublished events below maxAttempts without leasing them.
+func pending(t *testing.T, store *memory.Store, maxAttempts int) []entity.OutboxEvent {
+	t.Helper()
+	events, err := memory.NewOutboxRepository(store).ClaimPending(context.Background(), 100, maxAttempts, time.Time{})
+	if err != nil {
+		t.Fatalf("ClaimPending() error = %v", err)
+	}
+	return events
+}
+
+func newTestRelay(store *memory.Store, sink Sink, cfg RelayConfig) *Relay {
+	return NewRelay(memory.NewTransactor(store, nil), []Sink{sink}, cfg, broker.NewMemoryBroker(1), nil)
+}
+
+func TestRelayPublishesAndMarks(t *testing.T) {
+	store := memory.NewStore()
+	seedOutbox(t, store, 3)
+	sink := &fakeSink{}
+	relay := newTestRelay(store, sink, RelayConfig{BatchSize: 2, MaxAttempts: 3, Lease: time.Minute})
+
+	for i := 0; i < 2; i++ {
+		if err := relay.dispatch(context.Background()); err != nil {
+			t.Fatalf("dispatch() error = %v", err)
+		}
+	}
+
+	if got := sink.ids(); len(got) != 3 || got[0] != 1 || got[2] != 3 {
+		t.Errorf("published %v, want events 1-3 in order", got)
+	}
+	if left := pending(t, store, 3); len(left) != 0 {
+		t.Errorf("%d events still pending after publishing", len(left))
+	}
+}
+
+func TestRelayCountsFailedAttempts(t *testing.T) {
+	store := memory.NewStore()
+	seedOutbox(t, store, 1)
+	sink := &fakeSink{err: errors.New("partner down")}
+	relay := newTestRelay(store, sink, RelayConfig{BatchSize: 10, MaxAttempts: 2, Lease: time.Minute})
+
+	if err := relay.dispatch(context.Background()); err != nil {
+		t.Fatalf("dispatch() error = %v", err)
+	}
+	// A failed event is released straight away so the next poll retries it.
+	left := pending(t, store, 2)
+	if len(left) != 1 || left[0].Attempts != 1 || left[0].LastError != "fake: partner down" {
+		t.Fatalf("after one failure got %+v, want one event with 1 attempt and the sink error", left)
+	}
+
+	if err := relay.dispatch(context.Background()); err != nil {
+		t.Fatalf("dispatch() error = %v", err)
+	}
+	if left := pending(t, store, 2); len(left) != 0 {
+		t.Errorf("event is still retried after MaxAttempts: %+v", left)
+	}
+	if exhausted := pending(t, store, 3); len(exhausted) != 1 || exhausted[0].Attempts != 2 {
+		t.Errorf("exhausted event = %+v, want it kept with 2 attempts", exhausted)
+	}
+}
+
+func TestRelayStopsWhenLeaseRunsOut(t *testing.T) {
+	store := memory.NewStore()
+	seedOutbox(t, store, 5)
+	sink := &fakeSink{delay: 20 * time.Millisecond}
+	relay := newTestRelay(store, sink, RelayConfig{BatchSize: 10, MaxAttempts: 3, Lease: 30 * time.Millisecond})
+
+	if err := relay.dispatch(context.Background()); err != nil {
+		t.Fatalf("dispatch() error = %v", err)
+	}
+	published := len(sink.ids())
+	if published == 0 || published == 5 {
+		t.Fatalf("published %d of 5 events, want the batch cut short by the lease", published)
+	}
+
+	// The batch stops once its lease is over, which also frees the rest for
+	// the next claim.
+	sink.delay = 0
+	if err := relay.dispatch(context.Background()); err != nil {
+		t.Fatalf("dispatch() error = %v", err)
+	}
+	if got := sink.ids(); len(got) != 5 {
+		t.Errorf("published %v after the lease ran out, want all 5 events once", got)
+	}
+}
+
+func TestRelaysDoNotClaimTheSameEvents(t *testing.T) {
+	store := memory.NewStore()
+	seedOutbox(t, store, 20)
+	transactor := memory.NewTransactor(store, nil)
+	sinks := []*fakeSink{{delay: time.Millisecond}, {delay: time.Millisecond}}
+
+	var wg sync.WaitGroup
+	for _, sink := range sinks {
+		relay := NewRelay(transactor, []Sink{sink}, RelayConfig{BatchSize: 5, MaxAttempts: 3, Lease: time.Minute}, broker.NewMemoryBroker(1), nil)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 4; i++ {
+				if err := relay.dispatch(context.Background()); err != nil {
+					t.Errorf("dispatch() error = %v", err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	seen := make(map[uint]int)
+	for _, sink := range sinks {
+		for _, id := range sink.ids() {
+			seen[id]++
+		}
+	}
+	for id, count := range seen {
+		if count > 1 {
+			t.Errorf("event %d was published %d times", id, count)
+		}
+	}
+	if len(seen) != 20 {
+		t.Errorf("published %d distinct events, want 20", len(seen))
+	}
+}
+
+func TestRelayStaysReadyDuringSlowBatches(t *testing.T) {
+	store := memory.NewStore()
+	seedOutbox(t, store, 8)
+	// Each event takes longer than the poll interval, and the batch far
+	// longer than the heartbeat may be silent.
+	sink := &fakeSink{delay: 25 * time.Millisecond}
+	heartbeat := health.NewHeartbeat(40 * time.Millisecond)
+	relay := NewRelay(memory.NewTransactor(store, nil), []Sink{sink},
+		RelayConfig{PollInterval: 10 * time.Millisecond, BatchSize: 10, MaxAttempts: 3, Lease: time.Minute},
+		broker.NewMemoryBroker(1), heartbeat)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		relay.Run(ctx)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	deadline := time.Now().Add(time.Second)
+	for len(sink.ids()) < 8 {
+		if time.Now().After(deadline) {
+			t.Fatalf("published %d of 8 events", len(sink.ids()))
+		}
+		if err := heartbeat.Check(context.Background()); err != nil {
+			t.Fatalf("relay reported not ready while publishing: %v", err)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
@@ -0,0 +1,26 @@
+package outbox
+
+import (
+	"fmt"
+
+	"payment-gateway-manjo/backend/internal/infrastructure/config"
+)
+
+// NewSinks builds the sinks named in cfg.Sinks. The bus is shared with the
+// rest of the process so in-process subscribers can be registered on it.
+func NewSinks(cfg config.OutboxConfig, bus *Bus) ([]Sink, error) {
+	sinks := make([]Sink, 0, len(cfg.Sinks))
+	for _, name := range cfg.Sinks {
+		switch name {
+		case "log":
+			sinks = append(sinks, NewLogSink())
+		case "webhook":
+			sinks = append(sinks, NewWebhookSink(cfg.WebhookURL, cfg.WebhookSecret, cfg.WebhookTimeout))
+		case "bus":
+			sinks = append(sinks, bus)
+		default:
+			return nil, fmt.Errorf("unknown outbox sink %q", name)
+		}
+	}
+	return sinks, nil
+}
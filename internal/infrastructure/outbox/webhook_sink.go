@@ -0,0 +1,75 @@
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"payment-gateway-manjo/backend/internal/domain/entity"
+	"payment-gateway-manjo/backend/pkg/crypto"
+)
+
+type WebhookSink struct {
+	url       string
+	secretKey string
+	client    *http.Client
+}
+
+func NewWebhookSink(url, secretKey string, timeout time.Duration) *WebhookSink {
+	return &WebhookSink{
+		url:       url,
+		secretKey: secretKey,
+		client:    &http.Client{Timeout: timeout},
+	}
+}
+
+type webhookPayload struct {
+	EventID     uint            `json:"eventId"`
+	EventType   string          `json:"eventType"`
+	AggregateID string          `json:"aggregateId"`
+	OccurredAt  time.Time       `json:"occurredAt"`
+	Data        json.RawMessage `json:"data"`
+}
+
+func (s *WebhookSink) Name() string {
+	return "webhook"
+}
+
+// Publish posts the event to the configured URL. The body is signed with the
+// same HMAC scheme used for inbound requests and sent in X-Signature.
+func (s *WebhookSink) Publish(ctx context.Context, event entity.OutboxEvent) error {
+	body, err := json.Marshal(webhookPayload{
+		EventID:     event.ID,
+		EventType:   event.EventType,
+		AggregateID: event.AggregateID,
+		OccurredAt:  event.CreatedAt,
+		Data:        json.RawMessage(event.Payload),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal webhook payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to build webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Event-ID", strconv.FormatUint(uint64(event.ID), 10))
+	req.Header.Set("X-Event-Type", event.EventType)
+	req.Header.Set("X-Signature", crypto.GenerateSignature(string(body), s.secretKey))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
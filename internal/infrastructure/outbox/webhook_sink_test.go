@@ -0,0 +1,63 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"payment-gateway-manjo/backend/internal/domain/entity"
+	"payment-gateway-manjo/backend/pkg/crypto"
+)
+
+func TestWebhookSinkSignsTheBody(t *testing.T) {
+	var (
+		body    []byte
+		headers http.Header
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+		headers = r.Header
+	}))
+	t.Cleanup(server.Close)
+
+	event := entity.OutboxEvent{
+		ID:          42,
+		AggregateID: "R-1",
+		EventType:   entity.EventTransactionPaid,
+		Payload:     `{"reference_number":"R-1"}`,
+		CreatedAt:   time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC),
+	}
+	if err := NewWebhookSink(server.URL, "webhook-secret", time.Second).Publish(context.Background(), event); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+
+	if got, want := headers.Get("X-Signature"), crypto.GenerateSignature(string(body), "webhook-secret"); got != want {
+		t.Errorf("X-Signature = %q, want the HMAC of the raw body %q", got, want)
+	}
+	if headers.Get("X-Event-ID") != "42" || headers.Get("X-Event-Type") != entity.EventTransactionPaid {
+		t.Errorf("event headers = %v", headers)
+	}
+	var payload webhookPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		t.Fatalf("body is not JSON: %v", err)
+	}
+	if payload.EventID != 42 || payload.AggregateID != "R-1" || string(payload.Data) != event.Payload {
+		t.Errorf("payload = %+v", payload)
+	}
+}
+
+func TestWebhookSinkFailsOnNon2xx(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	t.Cleanup(server.Close)
+
+	err := NewWebhookSink(server.URL, "webhook-secret", time.Second).Publish(context.Background(), entity.OutboxEvent{ID: 1, Payload: "{}"})
+	if err == nil {
+		t.Fatal("Publish() succeeded against a 502")
+	}
+}
@@ -1,61 +1,276 @@
 package usecase
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"log/slog"
+	"strings"
 	"time"
 
+	"payment-gateway-manjo/backend/internal/domain/domainerr"
 	"payment-gateway-manjo/backend/internal/domain/entity"
 	"payment-gateway-manjo/backend/internal/domain/repository"
-
-	"gorm.io/gorm"
+	"payment-gateway-manjo/backend/internal/infrastructure/metrics"
+	"payment-gateway-manjo/backend/pkg/snaptime"
 )
 
 type PaymentUsecase interface {
-	ProcessPayment(referenceNo string, amount float64, status, paidTime string) (*entity.Transaction, error)
-	GetTransactions(merchantID, partnerRefNo, refNo, status string) ([]entity.Transaction, error)
+	ProcessPayment(ctx context.Context, referenceNo string, amount float64, status, paidTime string, audit entity.AuditInfo) (*entity.Transaction, error)
+	GetTransactions(ctx context.Context, merchantID, partnerRefNo, refNo, status string) ([]entity.Transaction, error)
+	GetTransactionHistory(ctx context.Context, referenceNo string) ([]entity.TransactionEvent, error)
+	GetStatusChanges(ctx context.Context, merchantID string, afterEventID uint, limit int) ([]entity.StatusChange, error)
+	GetStatusSnapshot(ctx context.Context, referenceNo string) (entity.StatusChange, error)
+	ExpireTransactions(ctx context.Context, cutoff time.Time, limit int) (int, error)
+	MarkFailed(ctx context.Context, referenceNo string, audit entity.AuditInfo) (*entity.Transaction, error)
 }
 
 type paymentUsecase struct {
-	transactionRepo repository.TransactionRepository
+	transactionRepo      repository.TransactionRepository
+	transactionEventRepo repository.TransactionEventRepository
+	transactor           repository.Transactor
+	timeouts             Timeouts
+	paidTimeMaxSkew      time.Duration
 }
 
-func NewPaymentUsecase(transactionRepo repository.TransactionRepository) PaymentUsecase {
+func NewPaymentUsecase(
+	transactionRepo repository.TransactionRepository,
+	transactionEventRepo repository.TransactionEventRepository,
+	transactor repository.Transactor,
+	timeouts Timeouts,
+	paidTimeMaxSkew time.Duration,
+) PaymentUsecase {
 	return &paymentUsecase{
-		transactionRepo: transactionRepo,
+		transactionRepo:      transactionRepo,
+		transactionEventRepo: transactionEventRepo,
+		transactor:           transactor,
+		timeouts:             timeouts,
+		paidTimeMaxSkew:      paidTimeMaxSkew,
 	}
 }
 
-func (u *paymentUsecase) ProcessPayment(referenceNo string, amount float64, status, paidTime string) (*entity.Transaction, error) {
-	transaction, err := u.transactionRepo.FindByReferenceNumber(referenceNo)
+func (u *paymentUsecase) ProcessPayment(ctx context.Context, referenceNo string, amount float64, status, paidTime string, audit entity.AuditInfo) (*entity.Transaction, error) {
+	ctx, cancel := withTimeout(ctx, u.timeouts.ProcessPayment)
+	defer cancel()
+
+	if status != entity.StatusSuccess && status != entity.StatusFailed {
+		return nil, domainerr.New(domainerr.ErrInvalidInput, "transactionStatusDesc must be %s or %s, got %q", entity.StatusSuccess, entity.StatusFailed, status)
+	}
+
+	receivedAt := time.Now()
+	parsedPaidTime, err := snaptime.Parse(paidTime)
+	if err != nil {
+		return nil, domainerr.New(domainerr.ErrInvalidInput, "invalid paidTime: %v", err)
+	}
+
+	transaction, err := u.transactionRepo.FindByReferenceNumber(ctx, referenceNo)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("transaction not found")
-		}
 		return nil, fmt.Errorf("failed to find transaction: %w", err)
 	}
 
 	if transaction.Amount != amount {
-		return nil, errors.New("amount mismatch")
+		return nil, domainerr.New(domainerr.ErrAmountMismatch, "amount %.2f does not match transaction amount", amount)
 	}
 
-	parsedPaidTime, err := time.Parse(time.RFC3339, paidTime)
-	if err != nil {
-		parsedPaidTime = time.Now()
+	switch transaction.Status {
+	case entity.StatusPending:
+	case entity.StatusExpired:
+		return nil, domainerr.New(domainerr.ErrExpired, "transaction %s has expired", referenceNo)
+	default:
+		// Acquirers retry notifications; repeating the final status is a no-op
+		// and, not being a change, is left out of the payment metrics.
+		if transaction.Status == status {
+			return transaction, nil
+		}
+		return nil, domainerr.New(domainerr.ErrInvalidState, "transaction %s is already %s", referenceNo, transaction.Status)
 	}
 
+	oldStatus := transaction.Status
 	transaction.Status = status
 	transaction.PaidDate = &parsedPaidTime
+	transaction.ReceivedAt = &receivedAt
+	transaction.PaidTimeFlag = u.paidTimeFlag(transaction.TransactionDate, parsedPaidTime, receivedAt)
+	if transaction.PaidTimeFlag != "" {
+		slog.WarnContext(ctx, "suspicious paid time",
+			"reference_no", referenceNo,
+			"paid_time", parsedPaidTime,
+			"transaction_date", transaction.TransactionDate,
+			"flag", transaction.PaidTimeFlag,
+		)
+	}
 
-	if err := u.transactionRepo.Update(transaction); err != nil {
+	if err := u.changeStatus(ctx, transaction, oldStatus, audit); err != nil {
 		return nil, fmt.Errorf("failed to update transaction: %w", err)
 	}
+	recordPayment(transaction)
 	return transaction, nil
 }
 
-func (u *paymentUsecase) GetTransactions(merchantID, partnerRefNo, refNo, status string) ([]entity.Transaction, error) {
+// recordPayment counts a payment notification that changed the transaction.
+func recordPayment(transaction *entity.Transaction) {
+	metrics.Payments.WithLabelValues(transaction.Status).Inc()
+	if transaction.Status == entity.StatusSuccess {
+		metrics.PaymentAmount.WithLabelValues(transaction.Currency).Add(transaction.Amount)
+	}
+	if transaction.PaidTimeFlag != "" {
+		metrics.PaidTimeAnomalies.WithLabelValues(transaction.PaidTimeFlag).Inc()
+	}
+}
+
+// paidTimeFlag reports a paid time that precedes the transaction or lies
+// further in the future than clock skew between us and the acquirer explains.
+func (u *paymentUsecase) paidTimeFlag(transactionDate, paidTime, receivedAt time.Time) string {
+	switch {
+	case paidTime.Before(transactionDate):
+		return entity.PaidTimeBeforeTransaction
+	case paidTime.After(receivedAt.Add(u.paidTimeMaxSkew)):
+		return entity.PaidTimeInFuture
+	default:
+		return ""
+	}
+}
+
+func (u *paymentUsecase) GetTransactions(ctx context.Context, merchantID, partnerRefNo, refNo, status string) ([]entity.Transaction, error) {
+	ctx, cancel := withTimeout(ctx, u.timeouts.Query)
+	defer cancel()
+
 	if merchantID == "" && partnerRefNo == "" && refNo == "" && status == "" {
-		return u.transactionRepo.FindAll()
+		return u.transactionRepo.FindAll(ctx)
+	}
+	return u.transactionRepo.FindByFilters(ctx, merchantID, partnerRefNo, refNo, status)
+}
+
+func (u *paymentUsecase) GetTransactionHistory(ctx context.Context, referenceNo string) ([]entity.TransactionEvent, error) {
+	ctx, cancel := withTimeout(ctx, u.timeouts.Query)
+	defer cancel()
+
+	transaction, err := u.transactionRepo.FindByReferenceNumber(ctx, referenceNo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find transaction: %w", err)
+	}
+	return u.transactionEventRepo.FindByTransactionID(ctx, transaction.ID)
+}
+
+// GetStatusChanges lets a merchant's real-time clients catch up on changes
+// they missed while disconnected.
+func (u *paymentUsecase) GetStatusChanges(ctx context.Context, merchantID string, afterEventID uint, limit int) ([]entity.StatusChange, error) {
+	ctx, cancel := withTimeout(ctx, u.timeouts.Query)
+	defer cancel()
+
+	changes, err := u.transactionEventRepo.FindChangesByMerchant(ctx, merchantID, afterEventID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find status changes: %w", err)
+	}
+	return changes, nil
+}
+
+// GetStatusSnapshot describes a transaction's current status as the change
+// that led to it. Both reads go to the primary, so a transaction created a
+// moment ago is found even when the read replica lags.
+func (u *paymentUsecase) GetStatusSnapshot(ctx context.Context, referenceNo string) (entity.StatusChange, error) {
+	ctx, cancel := withTimeout(ctx, u.timeouts.Query)
+	defer cancel()
+
+	transaction, err := u.transactionRepo.FindByReferenceNumber(ctx, referenceNo)
+	if err != nil {
+		return entity.StatusChange{}, fmt.Errorf("failed to find transaction: %w", err)
+	}
+	history, err := u.transactionEventRepo.FindByTransactionID(ctx, transaction.ID)
+	if err != nil {
+		return entity.StatusChange{}, fmt.Errorf("failed to find transaction history: %w", err)
+	}
+
+	if len(history) == 0 {
+		// Transactions created before history was recorded.
+		return entity.StatusChange{
+			ReferenceNumber:        transaction.ReferenceNumber,
+			PartnerReferenceNumber: transaction.PartnerReferenceNumber,
+			MerchantID:             transaction.MerchantID,
+			NewStatus:              transaction.Status,
+			Amount:                 transaction.Amount,
+			Currency:               transaction.Currency,
+			OccurredAt:             transaction.UpdatedAt,
+		}, nil
+	}
+	return entity.NewStatusChange(transaction, &history[len(history)-1]), nil
+}
+
+// ExpireTransactions marks up to limit pending transactions created before
+// cutoff as expired and returns how many were changed.
+func (u *paymentUsecase) ExpireTransactions(ctx context.Context, cutoff time.Time, limit int) (int, error) {
+	ctx, cancel := withTimeout(ctx, u.timeouts.ExpireBatch)
+	defer cancel()
+
+	transactions, err := u.transactionRepo.FindPendingBefore(ctx, cutoff, limit)
+	if err != nil {
+		return 0, fmt.Errorf("failed to find pending transactions: %w", err)
+	}
+
+	audit := entity.AuditInfo{Source: entity.SourceExpiryJob, Actor: "system"}
+	expired := 0
+	for i := range transactions {
+		transaction := &transactions[i]
+		transaction.Status = entity.StatusExpired
+		err := u.changeStatus(ctx, transaction, entity.StatusPending, audit)
+		if errors.Is(err, domainerr.ErrConflict) {
+			// Paid or changed since it was read; nothing left to expire.
+			continue
+		}
+		if err != nil {
+			return expired, fmt.Errorf("failed to expire transaction %s: %w", transaction.ReferenceNumber, err)
+		}
+		expired++
+	}
+	return expired, nil
+}
+
+// MarkFailed lets an operator fail a pending transaction the acquirer never
+// reported on. audit.Reason is required and ends up in the history.
+func (u *paymentUsecase) MarkFailed(ctx context.Context, referenceNo string, audit entity.AuditInfo) (*entity.Transaction, error) {
+	if strings.TrimSpace(audit.Reason) == "" {
+		return nil, domainerr.New(domainerr.ErrInvalidInput, "a reason is required to mark a transaction failed")
 	}
-	return u.transactionRepo.FindByFilters(merchantID, partnerRefNo, refNo, status)
-}
\ No newline at end of file
+	ctx, cancel := withTimeout(ctx, u.timeouts.ProcessPayment)
+	defer cancel()
+
+	transaction, err := u.transactionRepo.FindByReferenceNumber(ctx, referenceNo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find transaction: %w", err)
+	}
+	if transaction.Status != entity.StatusPending {
+		return nil, domainerr.New(domainerr.ErrInvalidState, "transaction %s is %s, only PENDING can be marked failed", referenceNo, transaction.Status)
+	}
+
+	transaction.Status = entity.StatusFailed
+	if err := u.changeStatus(ctx, transaction, entity.StatusPending, audit); err != nil {
+		return nil, fmt.Errorf("failed to update transaction: %w", err)
+	}
+	return transaction, nil
+}
+
+// changeStatus persists transaction together with its history entry, the
+// announcement to real-time subscribers and, for statuses that have one, the
+// matching outbox event.
+func (u *paymentUsecase) changeStatus(ctx context.Context, transaction *entity.Transaction, oldStatus string, audit entity.AuditInfo) error {
+	return u.transactor.WithinTransaction(ctx, func(repos repository.Repositories) error {
+		if err := repos.Transactions().Update(ctx, transaction, oldStatus); err != nil {
+			return err
+		}
+		history := entity.NewTransactionEvent(transaction, oldStatus, audit)
+		if err := repos.TransactionEvents().Create(ctx, history); err != nil {
+			return err
+		}
+		if err := repos.StatusChanges().Publish(ctx, entity.NewStatusChange(transaction, history)); err != nil {
+			return err
+		}
+		eventType, ok := entity.EventTypeForStatus(transaction.Status)
+		if !ok {
+			return nil
+		}
+		event, err := entity.NewOutboxEvent(eventType, transaction.ReferenceNumber, transaction)
+		if err != nil {
+			return err
+		}
+		return repos.Outbox().Add(ctx, event)
+	})
+}
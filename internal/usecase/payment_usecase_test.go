@@ -0,0 +1,482 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"payment-gateway-manjo/backend/internal/domain/domainerr"
+	"payment-gateway-manjo/backend/internal/domain/entity"
+	"payment-gateway-manjo/backend/internal/domain/repository"
+	"payment-gateway-manjo/backend/internal/infrastructure/memory"
+	"payment-gateway-manjo/backend/internal/infrastructure/metrics"
+	"payment-gateway-manjo/backend/pkg/snaptime"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+type paymentFixture struct {
+	store   *memory.Store
+	usecase PaymentUsecase
+}
+
+func newPaymentFixture(t *testing.T, transactions ...*entity.Transaction) paymentFixture {
+	t.Helper()
+	store := memory.NewStore()
+	repo := memory.NewTransactionRepository(store)
+	for _, transaction := range transactions {
+		if err := repo.Create(context.Background(), transaction); err != nil {
+			t.Fatalf("failed to seed %s: %v", transaction.ReferenceNumber, err)
+		}
+	}
+	return paymentFixture{
+		store: store,
+		usecase: NewPaymentUsecase(
+			repo,
+			memory.NewTransactionEventRepository(store),
+			memory.NewTransactor(store, nil),
+			Timeouts{},
+			5*time.Minute,
+		),
+	}
+}
+
+func pendingTransaction(refNo string, transactionDate time.Time) *entity.Transaction {
+	return &entity.Transaction{
+		MerchantID:             "M1",
+		Amount:                 15000,
+		Currency:               "IDR",
+		PartnerReferenceNumber: "P-" + refNo,
+		ReferenceNumber:        refNo,
+		Status:                 entity.StatusPending,
+		TransactionDate:        transactionDate,
+		CreatedAt:              transactionDate,
+	}
+}
+
+func TestProcessPayment(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	created := now.Add(-10 * time.Minute)
+	paidTime := snaptime.Format(now.Add(-time.Minute))
+	audit := entity.AuditInfo{Source: entity.SourceAcquirerNotification, Actor: "10.0.0.1"}
+
+	withStatus := func(status string) *entity.Transaction {
+		transaction := pendingTransaction("R-1", created)
+		transaction.Status = status
+		return transaction
+	}
+
+	tests := []struct {
+		name       string
+		seed       *entity.Transaction
+		refNo      string
+		amount     float64
+		status     string
+		paidTime   string
+		wantErr    error
+		wantStatus string
+		wantFlag   string
+		wantEvents int
+	}{
+		{
+			name: "pending to success", seed: withStatus(entity.StatusPending),
+			refNo: "R-1", amount: 15000, status: entity.StatusSuccess, paidTime: paidTime,
+			wantStatus: entity.StatusSuccess, wantEvents: 1,
+		},
+		{
+			name: "pending to failed", seed: withStatus(entity.StatusPending),
+			refNo: "R-1", amount: 15000, status: entity.StatusFailed, paidTime: paidTime,
+			wantStatus: entity.StatusFailed, wantEvents: 1,
+		},
+		{
+			name: "amount mismatch", seed: withStatus(entity.StatusPending),
+			refNo: "R-1", amount: 14999.99, status: entity.StatusSuccess, paidTime: paidTime,
+			wantErr: domainerr.ErrAmountMismatch, wantStatus: entity.StatusPending,
+		},
+		{
+			name: "not found", seed: withStatus(entity.StatusPending),
+			refNo: "R-404", amount: 15000, status: entity.StatusSuccess, paidTime: paidTime,
+			wantErr: domainerr.ErrNotFound, wantStatus: entity.StatusPending,
+		},
+		{
+			name: "expired", seed: withStatus(entity.StatusExpired),
+			refNo: "R-1", amount: 15000, status: entity.StatusSuccess, paidTime: paidTime,
+			wantErr: domainerr.ErrExpired, wantStatus: entity.StatusExpired,
+		},
+		{
+			name: "repeated final status is idempotent", seed: withStatus(entity.StatusSuccess),
+			refNo: "R-1", amount: 15000, status: entity.StatusSuccess, paidTime: paidTime,
+			wantStatus: entity.StatusSuccess,
+		},
+		{
+			name: "different final status", seed: withStatus(entity.StatusFailed),
+			refNo: "R-1", amount: 15000, status: entity.StatusSuccess, paidTime: paidTime,
+			wantErr: domainerr.ErrInvalidState, wantStatus: entity.StatusFailed,
+		},
+		{
+			name: "status an acquirer cannot report", seed: withStatus(entity.StatusPending),
+			refNo: "R-1", amount: 15000, status: entity.StatusExpired, paidTime: paidTime,
+			wantErr: domainerr.ErrInvalidInput, wantStatus: entity.StatusPending,
+		},
+		{
+			name: "unparseable paid time", seed: withStatus(entity.StatusPending),
+			refNo: "R-1", amount: 15000, status: entity.StatusSuccess, paidTime: "2024-03-01 10:00:00",
+			wantErr: domainerr.ErrInvalidInput, wantStatus: entity.StatusPending,
+		},
+		{
+			name: "paid time without offset", seed: withStatus(entity.StatusPending),
+			refNo: "R-1", amount: 15000, status: entity.StatusSuccess, paidTime: "2024-03-01T10:00:00Z",
+			wantErr: domainerr.ErrInvalidInput, wantStatus: entity.StatusPending,
+		},
+		{
+			name: "paid before the transaction was created", seed: withStatus(entity.StatusPending),
+			refNo: "R-1", amount: 15000, status: entity.StatusSuccess, paidTime: snaptime.Format(created.Add(-time.Hour)),
+			wantStatus: entity.StatusSuccess, wantFlag: entity.PaidTimeBeforeTransaction, wantEvents: 1,
+		},
+		{
+			name: "paid time in the future", seed: withStatus(entity.StatusPending),
+			refNo: "R-1", amount: 15000, status: entity.StatusSuccess, paidTime: snaptime.Format(now.Add(time.Hour)),
+			wantStatus: entity.StatusSuccess, wantFlag: entity.PaidTimeInFuture, wantEvents: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fixture := newPaymentFixture(t, tt.seed)
+			ctx := context.Background()
+
+			_, err := fixture.usecase.ProcessPayment(ctx, tt.refNo, tt.amount, tt.status, tt.paidTime, audit)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("ProcessPayment() error = %v, want %v", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Fatalf("ProcessPayment() error = %v", err)
+			}
+
+			stored, err := memory.NewTransactionRepository(fixture.store).FindByReferenceNumber(ctx, "R-1")
+			if err != nil {
+				t.Fatalf("FindByReferenceNumber() error = %v", err)
+			}
+			if stored.Status != tt.wantStatus {
+				t.Errorf("status = %s, want %s", stored.Status, tt.wantStatus)
+			}
+			if stored.PaidTimeFlag != tt.wantFlag {
+				t.Errorf("paid time flag = %q, want %q", stored.PaidTimeFlag, tt.wantFlag)
+			}
+
+			history, err := fixture.usecase.GetTransactionHistory(ctx, "R-1")
+			if err != nil {
+				t.Fatalf("GetTransactionHistory() error = %v", err)
+			}
+			if len(history) != tt.wantEvents {
+				t.Fatalf("history has %d events, want %d", len(history), tt.wantEvents)
+			}
+			if tt.wantEvents == 0 {
+				return
+			}
+
+			if stored.PaidDate == nil || stored.ReceivedAt == nil {
+				t.Fatalf("paid date %v and received at %v must both be set", stored.PaidDate, stored.ReceivedAt)
+			}
+			want, _ := snaptime.Parse(tt.paidTime)
+			if !stored.PaidDate.Equal(want) {
+				t.Errorf("paid date = %v, want the acquirer's %v", stored.PaidDate, want)
+			}
+			if event := history[0]; event.OldStatus != entity.StatusPending || event.NewStatus != tt.wantStatus || event.Actor != audit.Actor {
+				t.Errorf("history event = %+v", event)
+			}
+
+			outbox, err := memory.NewOutboxRepository(fixture.store).ClaimPending(ctx, 10, 1, time.Now())
+			if err != nil {
+				t.Fatalf("ClaimPending() error = %v", err)
+			}
+			wantType, _ := entity.EventTypeForStatus(tt.wantStatus)
+			if len(outbox) != 1 || outbox[0].EventType != wantType || outbox[0].AggregateID != "R-1" {
+				t.Errorf("outbox = %+v, want one %s event", outbox, wantType)
+			}
+		})
+	}
+}
+
+func TestProcessPaymentRetryIsNotCountedAgain(t *testing.T) {
+	now := time.Now()
+	fixture := newPaymentFixture(t, pendingTransaction("R-1", now.Add(-time.Minute)))
+	ctx := context.Background()
+	amount := metrics.PaymentAmount.WithLabelValues("IDR")
+	payments := metrics.Payments.WithLabelValues(entity.StatusSuccess)
+	amountBefore, paymentsBefore := testutil.ToFloat64(amount), testutil.ToFloat64(payments)
+
+	for i := 0; i < 3; i++ {
+		if _, err := fixture.usecase.ProcessPayment(ctx, "R-1", 15000, entity.StatusSuccess, snaptime.Format(now), entity.AuditInfo{}); err != nil {
+			t.Fatalf("ProcessPayment() attempt %d error = %v", i+1, err)
+		}
+	}
+
+	if got := testutil.ToFloat64(amount) - amountBefore; got != 15000 {
+		t.Errorf("paid amount grew by %.2f, want 15000.00 once", got)
+	}
+	if got := testutil.ToFloat64(payments) - paymentsBefore; got != 1 {
+		t.Errorf("payments grew by %.0f, want 1", got)
+	}
+}
+
+func TestGetTransactions(t *testing.T) {
+	base := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	seed := func(merchantID, refNo, status string, offset time.Duration) *entity.Transaction {
+		transaction := pendingTransaction(refNo, base.Add(offset))
+		transaction.MerchantID = merchantID
+		transaction.Status = status
+		return transaction
+	}
+	fixture := newPaymentFixture(t,
+		seed("M1", "R-1", entity.StatusPending, 0),
+		seed("M1", "R-2", entity.StatusSuccess, time.Minute),
+		seed("M2", "R-3", entity.StatusSuccess, 2*time.Minute),
+		seed("M2", "R-4", entity.StatusFailed, 3*time.Minute),
+	)
+
+	tests := []struct {
+		name                                    string
+		merchantID, partnerRefNo, refNo, status string
+		want                                    []string
+	}{
+		{name: "all", want: []string{"R-4", "R-3", "R-2", "R-1"}},
+		{name: "by merchant", merchantID: "M2", want: []string{"R-4", "R-3"}},
+		{name: "by status", status: entity.StatusSuccess, want: []string{"R-3", "R-2"}},
+		{name: "by merchant and status", merchantID: "M1", status: entity.StatusSuccess, want: []string{"R-2"}},
+		{name: "by partner reference", partnerRefNo: "P-R-3", want: []string{"R-3"}},
+		{name: "by reference", refNo: "R-1", want: []string{"R-1"}},
+		{name: "conflicting filters", merchantID: "M2", refNo: "R-1"},
+		{name: "unknown status", status: entity.StatusExpired},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transactions, err := fixture.usecase.GetTransactions(context.Background(), tt.merchantID, tt.partnerRefNo, tt.refNo, tt.status)
+			if err != nil {
+				t.Fatalf("GetTransactions() error = %v", err)
+			}
+			if len(transactions) != len(tt.want) {
+				t.Fatalf("got %d transactions, want %v", len(transactions), tt.want)
+			}
+			for i, want := range tt.want {
+				if transactions[i].ReferenceNumber != want {
+					t.Errorf("transactions[%d] = %s, want %s", i, transactions[i].ReferenceNumber, want)
+				}
+			}
+		})
+	}
+}
+
+func TestGetTransactionHistoryNotFound(t *testing.T) {
+	fixture := newPaymentFixture(t)
+	_, err := fixture.usecase.GetTransactionHistory(context.Background(), "R-404")
+	if !errors.Is(err, domainerr.ErrNotFound) {
+		t.Errorf("GetTransactionHistory() error = %v, want ErrNotFound", err)
+	}
+}
+
+// laggingReplica answers listing queries, which may go to a read replica, as
+// if nothing had been replicated yet.
+type laggingReplica struct {
+	repository.TransactionRepository
+}
+
+func (laggingReplica) FindAll(context.Context) ([]entity.Transaction, error) {
+	return nil, nil
+}
+
+func (laggingReplica) FindByFilters(context.Context, string, string, string, string) ([]entity.Transaction, error) {
+	return nil, nil
+}
+
+func TestGetStatusSnapshot(t *testing.T) {
+	store := memory.NewStore()
+	transactor := memory.NewTransactor(store, nil)
+	usecase := NewPaymentUsecase(
+		laggingReplica{memory.NewTransactionRepository(store)},
+		memory.NewTransactionEventRepository(store),
+		transactor,
+		Timeouts{},
+		5*time.Minute,
+	)
+	ctx := context.Background()
+
+	generated, err := NewQRGeneratorUsecase(transactor, Timeouts{}).GenerateQR(ctx, "M1", 15000, "IDR", "P-1")
+	if err != nil {
+		t.Fatalf("GenerateQR() error = %v", err)
+	}
+
+	snapshot, err := usecase.GetStatusSnapshot(ctx, generated.ReferenceNumber)
+	if err != nil {
+		t.Fatalf("GetStatusSnapshot() error = %v", err)
+	}
+	if snapshot.NewStatus != entity.StatusPending || snapshot.EventID == 0 || snapshot.MerchantID != "M1" {
+		t.Errorf("snapshot = %+v, want the PENDING change from generation", snapshot)
+	}
+
+	if _, err := usecase.GetStatusSnapshot(ctx, "R-404"); !errors.Is(err, domainerr.ErrNotFound) {
+		t.Errorf("GetStatusSnapshot() of an unknown reference: error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestExpireTransactions(t *testing.T) {
+	now := time.Now()
+	paid := pendingTransaction("R-3", now.Add(-time.Hour))
+	paid.Status = entity.StatusSuccess
+	fixture := newPaymentFixture(t,
+		pendingTransaction("R-1", now.Add(-time.Hour)),
+		pendingTransaction("R-2", now.Add(-time.Minute)),
+		paid,
+	)
+	ctx := context.Background()
+
+	expired, err := fixture.usecase.ExpireTransactions(ctx, now.Add(-15*time.Minute), 10)
+	if err != nil {
+		t.Fatalf("ExpireTransactions() error = %v", err)
+	}
+	if expired != 1 {
+		t.Errorf("expired %d transactions, want 1", expired)
+	}
+
+	repo := memory.NewTransactionRepository(fixture.store)
+	for refNo, want := range map[string]string{
+		"R-1": entity.StatusExpired,
+		"R-2": entity.StatusPending,
+		"R-3": entity.StatusSuccess,
+	} {
+		stored, err := repo.FindByReferenceNumber(ctx, refNo)
+		if err != nil {
+			t.Fatalf("FindByReferenceNumber(%s) error = %v", refNo, err)
+		}
+		if stored.Status != want {
+			t.Errorf("%s status = %s, want %s", refNo, stored.Status, want)
+		}
+	}
+
+	_, err = fixture.usecase.ProcessPayment(ctx, "R-1", 15000, entity.StatusSuccess, snaptime.Format(now), entity.AuditInfo{})
+	if !errors.Is(err, domainerr.ErrExpired) {
+		t.Errorf("paying an expired transaction: error = %v, want ErrExpired", err)
+	}
+}
+
+// paidDuringExpiry pays a transaction between the expiry run reading it as
+// pending and writing it as expired.
+type paidDuringExpiry struct {
+	repository.TransactionRepository
+	pay func()
+}
+
+func (r paidDuringExpiry) FindPendingBefore(ctx context.Context, cutoff time.Time, limit int) ([]entity.Transaction, error) {
+	transactions, err := r.TransactionRepository.FindPendingBefore(ctx, cutoff, limit)
+	r.pay()
+	return transactions, err
+}
+
+func TestExpireTransactionsRacingPayment(t *testing.T) {
+	now := time.Now()
+	fixture := newPaymentFixture(t, pendingTransaction("R-1", now.Add(-time.Hour)))
+	ctx := context.Background()
+	events := memory.NewTransactionEventRepository(fixture.store)
+
+	expiry := NewPaymentUsecase(
+		paidDuringExpiry{
+			TransactionRepository: memory.NewTransactionRepository(fixture.store),
+			pay: func() {
+				if _, err := fixture.usecase.ProcessPayment(ctx, "R-1", 15000, entity.StatusSuccess, snaptime.Format(now), entity.AuditInfo{}); err != nil {
+					t.Fatalf("ProcessPayment() error = %v", err)
+				}
+			},
+		},
+		events,
+		memory.NewTransactor(fixture.store, nil),
+		Timeouts{},
+		5*time.Minute,
+	)
+
+	expired, err := expiry.ExpireTransactions(ctx, now.Add(-15*time.Minute), 10)
+	if err != nil {
+		t.Fatalf("ExpireTransactions() error = %v", err)
+	}
+	if expired != 0 {
+		t.Errorf("expired %d transactions, want 0", expired)
+	}
+
+	stored, err := memory.NewTransactionRepository(fixture.store).FindByReferenceNumber(ctx, "R-1")
+	if err != nil {
+		t.Fatalf("FindByReferenceNumber() error = %v", err)
+	}
+	if stored.Status != entity.StatusSuccess {
+		t.Errorf("status = %s, want the payment's %s", stored.Status, entity.StatusSuccess)
+	}
+	history, err := events.FindByTransactionID(ctx, stored.ID)
+	if err != nil {
+		t.Fatalf("FindByTransactionID() error = %v", err)
+	}
+	if len(history) != 1 || history[0].NewStatus != entity.StatusSuccess {
+		t.Errorf("history = %+v, want only the payment", history)
+	}
+}
+
+func TestMarkFailed(t *testing.T) {
+	now := time.Now()
+	paid := pendingTransaction("R-2", now)
+	paid.Status = entity.StatusSuccess
+	audit := entity.AuditInfo{Source: entity.SourceAdmin, Actor: "ops", Reason: "acquirer confirmed no payment"}
+
+	tests := []struct {
+		name       string
+		refNo      string
+		audit      entity.AuditInfo
+		wantErr    error
+		wantStatus string
+	}{
+		{name: "pending", refNo: "R-1", audit: audit, wantStatus: entity.StatusFailed},
+		{name: "without a reason", refNo: "R-1", audit: entity.AuditInfo{Source: entity.SourceAdmin, Actor: "ops", Reason: " "}, wantErr: domainerr.ErrInvalidInput, wantStatus: entity.StatusPending},
+		{name: "already paid", refNo: "R-2", audit: audit, wantErr: domainerr.ErrInvalidState, wantStatus: entity.StatusSuccess},
+		{name: "not found", refNo: "R-404", audit: audit, wantErr: domainerr.ErrNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fixture := newPaymentFixture(t, pendingTransaction("R-1", now), paid)
+			ctx := context.Background()
+
+			_, err := fixture.usecase.MarkFailed(ctx, tt.refNo, tt.audit)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("MarkFailed() error = %v, want %v", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Fatalf("MarkFailed() error = %v", err)
+			}
+			if tt.wantStatus == "" {
+				return
+			}
+
+			stored, err := memory.NewTransactionRepository(fixture.store).FindByReferenceNumber(ctx, tt.refNo)
+			if err != nil {
+				t.Fatalf("FindByReferenceNumber() error = %v", err)
+			}
+			if stored.Status != tt.wantStatus {
+				t.Errorf("status = %s, want %s", stored.Status, tt.wantStatus)
+			}
+
+			history, err := fixture.usecase.GetTransactionHistory(ctx, tt.refNo)
+			if err != nil {
+				t.Fatalf("GetTransactionHistory() error = %v", err)
+			}
+			if tt.wantErr != nil {
+				if len(history) != 0 {
+					t.Errorf("history = %+v, want no events", history)
+				}
+				return
+			}
+			if len(history) != 1 || history[0].Source != entity.SourceAdmin || history[0].Actor != "ops" || history[0].Reason != audit.Reason {
+				t.Errorf("history = %+v, want one admin event with the reason", history)
+			}
+		})
+	}
+}
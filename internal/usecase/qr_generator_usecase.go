@@ -1,36 +1,43 @@
 package usecase
 
 import (
-	"errors"
+	"context"
 	"fmt"
 	"time"
 
+	"payment-gateway-manjo/backend/internal/domain/domainerr"
 	"payment-gateway-manjo/backend/internal/domain/entity"
 	"payment-gateway-manjo/backend/internal/domain/repository"
+	"payment-gateway-manjo/backend/pkg/qris"
 
 	"github.com/google/uuid"
 )
 
 type QRGeneratorUsecase interface {
-	GenerateQR(merchantID string, amount float64, currency, partnerRefNo string) (*entity.Transaction, error)
+	GenerateQR(ctx context.Context, merchantID string, amount float64, currency, partnerRefNo string) (*entity.Transaction, error)
 }
 
 type qrGeneratorUsecase struct {
-	transactionRepo repository.TransactionRepository
+	transactor repository.Transactor
+	timeouts   Timeouts
 }
 
-func NewQRGeneratorUsecase(transactionRepo repository.TransactionRepository) QRGeneratorUsecase {
+func NewQRGeneratorUsecase(transactor repository.Transactor, timeouts Timeouts) QRGeneratorUsecase {
 	return &qrGeneratorUsecase{
-		transactionRepo: transactionRepo,
+		transactor: transactor,
+		timeouts:   timeouts,
 	}
 }
 
-func (u *qrGeneratorUsecase) GenerateQR(merchantID string, amount float64, currency, partnerRefNo string) (*entity.Transaction, error) {
+func (u *qrGeneratorUsecase) GenerateQR(ctx context.Context, merchantID string, amount float64, currency, partnerRefNo string) (*entity.Transaction, error) {
 	if amount <= 0 {
-		return nil, errors.New("amount must be greater than 0")
+		return nil, domainerr.New(domainerr.ErrInvalidInput, "amount must be greater than 0")
 	}
+	ctx, cancel := withTimeout(ctx, u.timeouts.GenerateQR)
+	defer cancel()
+
 	referenceNumber := generateReferenceNumber()
-	qrContent := generateQRContent(merchantID, referenceNumber, amount)
+	qrContent := qris.Encode(merchantID, referenceNumber, amount)
 
 	transaction := &entity.Transaction{
 		MerchantID:             merchantID,
@@ -43,7 +50,25 @@ func (u *qrGeneratorUsecase) GenerateQR(merchantID string, amount float64, curre
 		QRContent:              qrContent,
 	}
 
-	if err := u.transactionRepo.Create(transaction); err != nil {
+	err := u.transactor.WithinTransaction(ctx, func(repos repository.Repositories) error {
+		if err := repos.Transactions().Create(ctx, transaction); err != nil {
+			return err
+		}
+		audit := entity.AuditInfo{Source: entity.SourceQRGeneration, Actor: merchantID}
+		history := entity.NewTransactionEvent(transaction, "", audit)
+		if err := repos.TransactionEvents().Create(ctx, history); err != nil {
+			return err
+		}
+		if err := repos.StatusChanges().Publish(ctx, entity.NewStatusChange(transaction, history)); err != nil {
+			return err
+		}
+		event, err := entity.NewOutboxEvent(entity.EventTransactionCreated, transaction.ReferenceNumber, transaction)
+		if err != nil {
+			return err
+		}
+		return repos.Outbox().Add(ctx, event)
+	})
+	if err != nil {
 		return nil, fmt.Errorf("failed to create transaction: %w", err)
 	}
 
@@ -55,9 +80,3 @@ func generateReferenceNumber() string {
 	shortUUID := uuid[:10]
 	return "A" + shortUUID
 }
-
-func generateQRContent(merchantID, referenceNumber string, amount float64) string {
-    amountStr := fmt.Sprintf("%.2f", amount)
-    return fmt.Sprintf("00020101021226620015ID.CO.MANJO.WWW01189360085801751859910210%s0303UMI51530014ID.CO.QRIS.WWW0215ID102106515192304121.0.21.09.255204481653033605502015802ID5904OLDI6013JAKARTA BARAT61051147062454%02d%s62460525%s07031110806ASPI663040FAD",
-        merchantID, len(amountStr), amountStr, referenceNumber)
-}
\ No newline at end of file
@@ -0,0 +1,83 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"payment-gateway-manjo/backend/internal/domain/domainerr"
+	"payment-gateway-manjo/backend/internal/domain/entity"
+	"payment-gateway-manjo/backend/internal/infrastructure/memory"
+)
+
+func TestGenerateQR(t *testing.T) {
+	tests := []struct {
+		name         string
+		amount       float64
+		partnerRefNo string
+		wantErr      error
+	}{
+		{name: "creates a pending transaction", amount: 25000, partnerRefNo: "P-NEW"},
+		{name: "zero amount", amount: 0, partnerRefNo: "P-NEW", wantErr: domainerr.ErrInvalidInput},
+		{name: "negative amount", amount: -1, partnerRefNo: "P-NEW", wantErr: domainerr.ErrInvalidInput},
+		{name: "duplicate partner reference number", amount: 25000, partnerRefNo: "P-TAKEN", wantErr: domainerr.ErrDuplicate},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			store := memory.NewStore()
+			repo := memory.NewTransactionRepository(store)
+			existing := pendingTransaction("R-TAKEN", time.Now())
+			existing.PartnerReferenceNumber = "P-TAKEN"
+			if err := repo.Create(ctx, existing); err != nil {
+				t.Fatalf("failed to seed: %v", err)
+			}
+			outbox := memory.NewOutboxRepository(store)
+			before, _ := outbox.ClaimPending(ctx, 100, 1, time.Now())
+
+			usecase := NewQRGeneratorUsecase(memory.NewTransactor(store, nil), Timeouts{})
+			transaction, err := usecase.GenerateQR(ctx, "M1", tt.amount, "IDR", tt.partnerRefNo)
+
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("GenerateQR() error = %v, want %v", err, tt.wantErr)
+				}
+				after, _ := outbox.ClaimPending(ctx, 100, 1, time.Now())
+				if len(after) != len(before) {
+					t.Errorf("outbox grew from %d to %d events on a failed generation", len(before), len(after))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GenerateQR() error = %v", err)
+			}
+
+			stored, err := repo.FindByReferenceNumber(ctx, transaction.ReferenceNumber)
+			if err != nil {
+				t.Fatalf("FindByReferenceNumber() error = %v", err)
+			}
+			if stored.Status != entity.StatusPending || stored.Amount != tt.amount || stored.PartnerReferenceNumber != tt.partnerRefNo {
+				t.Errorf("stored %+v", stored)
+			}
+			if !strings.Contains(stored.QRContent, transaction.ReferenceNumber) || !strings.Contains(stored.QRContent, "25000.00") {
+				t.Errorf("QR content %q does not carry the reference number and amount", stored.QRContent)
+			}
+
+			history, err := memory.NewTransactionEventRepository(store).FindByTransactionID(ctx, stored.ID)
+			if err != nil {
+				t.Fatalf("FindByTransactionID() error = %v", err)
+			}
+			if len(history) != 1 || history[0].NewStatus != entity.StatusPending || history[0].Source != entity.SourceQRGeneration {
+				t.Errorf("history = %+v, want one QR generation event", history)
+			}
+
+			events, _ := outbox.ClaimPending(ctx, 100, 1, time.Now())
+			if len(events) != 1 || events[0].EventType != entity.EventTransactionCreated {
+				t.Errorf("outbox = %+v, want one %s event", events, entity.EventTransactionCreated)
+			}
+		})
+	}
+}
@@ -0,0 +1,22 @@
+package usecase
+
+import (
+	"context"
+	"time"
+)
+
+// Timeouts bounds how long each usecase operation may spend, including every
+// repository call it makes. A zero value means no deadline beyond the caller's.
+type Timeouts struct {
+	GenerateQR     time.Duration
+	ProcessPayment time.Duration
+	Query          time.Duration
+	ExpireBatch    time.Duration
+}
+
+func withTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
@@ -0,0 +1,122 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"payment-gateway-manjo/backend/internal/domain/entity"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("payment-gateway-manjo/backend/internal/usecase")
+
+// endSpan records err on span, if any, and ends it.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+type tracedQRGeneratorUsecase struct {
+	next QRGeneratorUsecase
+}
+
+// NewTracedQRGeneratorUsecase wraps next so every call opens a span that
+// parents the repository spans created underneath it.
+func NewTracedQRGeneratorUsecase(next QRGeneratorUsecase) QRGeneratorUsecase {
+	return &tracedQRGeneratorUsecase{next: next}
+}
+
+func (u *tracedQRGeneratorUsecase) GenerateQR(ctx context.Context, merchantID string, amount float64, currency, partnerRefNo string) (*entity.Transaction, error) {
+	ctx, span := tracer.Start(ctx, "QRGeneratorUsecase.GenerateQR", trace.WithAttributes(
+		attribute.String("merchant.id", merchantID),
+		attribute.String("transaction.partner_reference_no", partnerRefNo),
+		attribute.String("transaction.currency", currency),
+	))
+	transaction, err := u.next.GenerateQR(ctx, merchantID, amount, currency, partnerRefNo)
+	if err == nil {
+		span.SetAttributes(attribute.String("transaction.reference_no", transaction.ReferenceNumber))
+	}
+	endSpan(span, err)
+	return transaction, err
+}
+
+type tracedPaymentUsecase struct {
+	next PaymentUsecase
+}
+
+// NewTracedPaymentUsecase wraps next so every call opens a span that parents
+// the repository spans created underneath it.
+func NewTracedPaymentUsecase(next PaymentUsecase) PaymentUsecase {
+	return &tracedPaymentUsecase{next: next}
+}
+
+func (u *tracedPaymentUsecase) ProcessPayment(ctx context.Context, referenceNo string, amount float64, status, paidTime string, audit entity.AuditInfo) (*entity.Transaction, error) {
+	ctx, span := tracer.Start(ctx, "PaymentUsecase.ProcessPayment", trace.WithAttributes(
+		attribute.String("transaction.reference_no", referenceNo),
+		attribute.String("transaction.status", status),
+	))
+	transaction, err := u.next.ProcessPayment(ctx, referenceNo, amount, status, paidTime, audit)
+	endSpan(span, err)
+	return transaction, err
+}
+
+func (u *tracedPaymentUsecase) GetTransactions(ctx context.Context, merchantID, partnerRefNo, refNo, status string) ([]entity.Transaction, error) {
+	ctx, span := tracer.Start(ctx, "PaymentUsecase.GetTransactions")
+	transactions, err := u.next.GetTransactions(ctx, merchantID, partnerRefNo, refNo, status)
+	span.SetAttributes(attribute.Int("transaction.count", len(transactions)))
+	endSpan(span, err)
+	return transactions, err
+}
+
+func (u *tracedPaymentUsecase) GetTransactionHistory(ctx context.Context, referenceNo string) ([]entity.TransactionEvent, error) {
+	ctx, span := tracer.Start(ctx, "PaymentUsecase.GetTransactionHistory", trace.WithAttributes(
+		attribute.String("transaction.reference_no", referenceNo),
+	))
+	events, err := u.next.GetTransactionHistory(ctx, referenceNo)
+	endSpan(span, err)
+	return events, err
+}
+
+func (u *tracedPaymentUsecase) GetStatusChanges(ctx context.Context, merchantID string, afterEventID uint, limit int) ([]entity.StatusChange, error) {
+	ctx, span := tracer.Start(ctx, "PaymentUsecase.GetStatusChanges", trace.WithAttributes(
+		attribute.String("merchant.id", merchantID),
+		attribute.Int64("transaction.after_event_id", int64(afterEventID)),
+	))
+	changes, err := u.next.GetStatusChanges(ctx, merchantID, afterEventID, limit)
+	endSpan(span, err)
+	return changes, err
+}
+
+func (u *tracedPaymentUsecase) GetStatusSnapshot(ctx context.Context, referenceNo string) (entity.StatusChange, error) {
+	ctx, span := tracer.Start(ctx, "PaymentUsecase.GetStatusSnapshot", trace.WithAttributes(
+		attribute.String("transaction.reference_no", referenceNo),
+	))
+	snapshot, err := u.next.GetStatusSnapshot(ctx, referenceNo)
+	endSpan(span, err)
+	return snapshot, err
+}
+
+func (u *tracedPaymentUsecase) ExpireTransactions(ctx context.Context, cutoff time.Time, limit int) (int, error) {
+	ctx, span := tracer.Start(ctx, "PaymentUsecase.ExpireTransactions")
+	expired, err := u.next.ExpireTransactions(ctx, cutoff, limit)
+	span.SetAttributes(attribute.Int("transaction.expired", expired))
+	endSpan(span, err)
+	return expired, err
+}
+
+func (u *tracedPaymentUsecase) MarkFailed(ctx context.Context, referenceNo string, audit entity.AuditInfo) (*entity.Transaction, error) {
+	ctx, span := tracer.Start(ctx, "PaymentUsecase.MarkFailed", trace.WithAttributes(
+		attribute.String("transaction.reference_no", referenceNo),
+		attribute.String("audit.actor", audit.Actor),
+	))
+	transaction, err := u.next.MarkFailed(ctx, referenceNo, audit)
+	endSpan(span, err)
+	return transaction, err
+}
@@ -0,0 +1,196 @@
+// Package client is the Go SDK for the payment gateway API. It signs requests
+// with pkg/crypto, retries transient failures and turns SNAP response codes
+// into typed errors, so callers no longer re-implement any of it.
+//
+//	c := client.New(client.Config{BaseURL: "https://gateway.internal", SecretKey: secret})
+//	qr, err := c.GenerateQR(ctx, client.GenerateQRRequest{...})
+//	if errors.Is(err, client.ErrDuplicate) { ... }
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"payment-gateway-manjo/backend/pkg/crypto"
+
+	"github.com/google/uuid"
+)
+
+// IdempotencyHeader carries the key that stays the same across every retry of
+// one call. The gateway deduplicates on partnerReferenceNo today; the key lets
+// both sides correlate retries in their logs.
+const IdempotencyHeader = "X-External-ID"
+
+type Config struct {
+	BaseURL   string
+	SecretKey string
+	// HTTPClient defaults to a client with a 10 second timeout.
+	HTTPClient *http.Client
+	// MaxRetries is how often a transient failure is retried; 0 means the
+	// default of 3, a negative value disables retries.
+	MaxRetries int
+	// RetryBackoff is the wait before the first retry, doubled for each
+	// further one. Defaults to 200ms.
+	RetryBackoff time.Duration
+	// LegacySignature signs GenerateQR with the old merchantId|amount|
+	// partnerReferenceNo string instead of the raw body.
+	LegacySignature bool
+}
+
+type Client struct {
+	baseURL         string
+	secretKey       string
+	httpClient      *http.Client
+	maxRetries      int
+	retryBackoff    time.Duration
+	legacySignature bool
+}
+
+func New(cfg Config) *Client {
+	c := &Client{
+		baseURL:         strings.TrimRight(cfg.BaseURL, "/"),
+		secretKey:       cfg.SecretKey,
+		httpClient:      cfg.HTTPClient,
+		maxRetries:      cfg.MaxRetries,
+		retryBackoff:    cfg.RetryBackoff,
+		legacySignature: cfg.LegacySignature,
+	}
+	if c.httpClient == nil {
+		c.httpClient = &http.Client{Timeout: 10 * time.Second}
+	}
+	if c.maxRetries == 0 {
+		c.maxRetries = 3
+	}
+	if c.maxRetries < 0 {
+		c.maxRetries = 0
+	}
+	if c.retryBackoff <= 0 {
+		c.retryBackoff = 200 * time.Millisecond
+	}
+	return c
+}
+
+// call describes one API call. signature overrides the raw body signature
+// for endpoints that still accept the legacy field string.
+type call struct {
+	method    string
+	path      string
+	query     url.Values
+	body      interface{}
+	signature string
+}
+
+// do sends the call, retrying transport errors and retryable responses with
+// the same idempotency key, and decodes a successful body into out.
+func (c *Client) do(ctx context.Context, req call, out interface{}) error {
+	var raw []byte
+	if req.body != nil {
+		var err error
+		if raw, err = json.Marshal(req.body); err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+	}
+	key := uuid.New().String()
+
+	var lastErr error
+	for attempt := 0; attempt <= c.maxRetries; attempt++ {
+		if attempt > 0 {
+			if err := c.wait(ctx, attempt); err != nil {
+				return errors.Join(lastErr, err)
+			}
+		}
+
+		lastErr = c.send(ctx, req, raw, key, out)
+		if lastErr == nil || ctx.Err() != nil || !retryable(lastErr) {
+			return lastErr
+		}
+	}
+	return lastErr
+}
+
+func (c *Client) send(ctx context.Context, req call, raw []byte, key string, out interface{}) error {
+	target := c.baseURL + req.path
+	if len(req.query) > 0 {
+		target += "?" + req.query.Encode()
+	}
+	var body io.Reader
+	if raw != nil {
+		body = bytes.NewReader(raw)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, req.method, target, body)
+	if err != nil {
+		return fmt.Errorf("failed to build request: %w", err)
+	}
+	httpReq.Header.Set("Accept", "application/json")
+	httpReq.Header.Set(IdempotencyHeader, key)
+	if raw != nil {
+		signed := string(raw)
+		if req.signature != "" {
+			signed = req.signature
+		}
+		httpReq.Header.Set("Content-Type", "application/json")
+		httpReq.Header.Set("X-Signature", crypto.GenerateSignature(signed, c.secretKey))
+	}
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return &transportError{err: err}
+	}
+	defer resp.Body.Close()
+
+	payload, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return &transportError{err: fmt.Errorf("failed to read response: %w", err)}
+	}
+	if resp.StatusCode >= 300 {
+		return newError(resp.StatusCode, payload)
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(payload, out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// wait sleeps before a retry with exponential backoff and full jitter.
+func (c *Client) wait(ctx context.Context, attempt int) error {
+	backoff := c.retryBackoff << (attempt - 1)
+	timer := time.NewTimer(backoff/2 + rand.N(backoff/2+1))
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// transportError is a request that never produced a response.
+type transportError struct {
+	err error
+}
+
+func (e *transportError) Error() string { return e.err.Error() }
+func (e *transportError) Unwrap() error { return e.err }
+
+// retryable reports whether a call can be sent again: it never reached the
+// gateway or failed in a way the gateway marks as temporary.
+func retryable(err error) bool {
+	var transport *transportError
+	if errors.As(err, &transport) {
+		return true
+	}
+	var apiErr *Error
+	return errors.As(err, &apiErr) && apiErr.Temporary()
+}
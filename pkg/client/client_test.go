@@ -0,0 +1,265 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"payment-gateway-manjo/backend/internal/delivery/http/router"
+	"payment-gateway-manjo/backend/internal/infrastructure/broker"
+	"payment-gateway-manjo/backend/internal/infrastructure/config"
+	"payment-gateway-manjo/backend/internal/infrastructure/health"
+	"payment-gateway-manjo/backend/internal/infrastructure/memory"
+	"payment-gateway-manjo/backend/internal/usecase"
+	"payment-gateway-manjo/backend/pkg/crypto"
+
+	"github.com/gin-gonic/gin"
+)
+
+const testSecret = "sdk-secret"
+
+// newGateway serves the real router on the in-memory repositories, so these
+// tests break when the SDK and the API drift apart.
+func newGateway(t *testing.T) *Client {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	cfg := &config.Config{
+		Security: config.SecurityConfig{SecretKey: testSecret, MaxBodyBytes: 1 << 20},
+		Tracing:  config.TracingConfig{ServiceName: "payment-gateway-test"},
+	}
+	store := memory.NewStore()
+	statusChanges := broker.NewMemoryBroker(16)
+	transactor := memory.NewTransactor(store, statusChanges)
+	engine, err := router.New(cfg, router.Dependencies{
+		QRUsecase: usecase.NewQRGeneratorUsecase(transactor, usecase.Timeouts{}),
+		PaymentUsecase: usecase.NewPaymentUsecase(
+			memory.NewTransactionRepository(store),
+			memory.NewTransactionEventRepository(store),
+			transactor,
+			usecase.Timeouts{},
+			time.Minute,
+		),
+		StatusChanges: statusChanges,
+		Probe:         health.NewProbe(time.Second),
+		Logger:        slog.New(slog.NewTextHandler(io.Discard, nil)),
+	})
+	if err != nil {
+		t.Fatalf("failed to build router: %v", err)
+	}
+	server := httptest.NewServer(engine)
+	t.Cleanup(server.Close)
+	return New(Config{BaseURL: server.URL, SecretKey: testSecret, RetryBackoff: time.Millisecond})
+}
+
+func generateRequest(partnerReferenceNo, value string) GenerateQRRequest {
+	return GenerateQRRequest{
+		MerchantID:         "MERCHANT-1",
+		PartnerReferenceNo: partnerReferenceNo,
+		Amount:             Amount{Value: value, Currency: "IDR"},
+	}
+}
+
+func TestAgainstGateway(t *testing.T) {
+	ctx := context.Background()
+	c := newGateway(t)
+
+	qr, err := c.GenerateQR(ctx, generateRequest("PARTNER-1", "15000.00"))
+	if err != nil {
+		t.Fatalf("GenerateQR: %v", err)
+	}
+	if qr.ResponseCode != "2004700" || qr.QRContent == "" {
+		t.Fatalf("GenerateQR = %+v", qr)
+	}
+
+	byReference, err := c.Query(ctx, qr.ReferenceNo, "")
+	if err != nil {
+		t.Fatalf("Query by reference: %v", err)
+	}
+	byPartner, err := c.Query(ctx, "", "PARTNER-1")
+	if err != nil {
+		t.Fatalf("Query by partner reference: %v", err)
+	}
+	if byReference.ID != byPartner.ID || byReference.Status != "PENDING" || byReference.Amount != 15000 {
+		t.Errorf("Query returned %+v and %+v", byReference, byPartner)
+	}
+
+	if _, err := c.Query(ctx, "A-missing", ""); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Query unknown reference error = %v, want ErrNotFound", err)
+	}
+
+	listed, err := c.ListTransactions(ctx, ListFilter{MerchantID: "MERCHANT-1", Status: "PENDING"})
+	if err != nil || len(listed) != 1 {
+		t.Errorf("ListTransactions = %d transactions, %v; want 1", len(listed), err)
+	}
+
+	history, err := c.History(ctx, qr.ReferenceNo)
+	if err != nil || len(history) != 1 || history[0].NewStatus != "PENDING" {
+		t.Errorf("History = %+v, %v", history, err)
+	}
+	if _, err := c.History(ctx, "A-missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("History unknown reference error = %v, want ErrNotFound", err)
+	}
+
+	if _, err := c.GenerateQR(ctx, generateRequest("PARTNER-2", "15000")); !errors.Is(err, ErrValidation) {
+		t.Errorf("GenerateQR with malformed amount error = %v, want ErrValidation", err)
+	}
+}
+
+func TestCancelAndRefundAreNotSent(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+	}))
+	t.Cleanup(server.Close)
+	c := New(Config{BaseURL: server.URL, SecretKey: testSecret})
+
+	if _, err := c.Cancel(context.Background(), CancelRequest{OriginalReferenceNo: "A000000001"}); !errors.Is(err, ErrNotSupported) {
+		t.Errorf("Cancel error = %v, want ErrNotSupported", err)
+	}
+	if _, err := c.Refund(context.Background(), RefundRequest{OriginalReferenceNo: "A000000001"}); !errors.Is(err, ErrNotSupported) {
+		t.Errorf("Refund error = %v, want ErrNotSupported", err)
+	}
+}
+
+func TestGenerateQRReplay(t *testing.T) {
+	ctx := context.Background()
+	c := newGateway(t)
+
+	first, err := c.GenerateQR(ctx, generateRequest("PARTNER-1", "15000.00"))
+	if err != nil {
+		t.Fatalf("GenerateQR: %v", err)
+	}
+
+	replayed, err := c.GenerateQR(ctx, generateRequest("PARTNER-1", "15000.00"))
+	if err != nil {
+		t.Fatalf("replayed GenerateQR: %v", err)
+	}
+	if *replayed != *first {
+		t.Errorf("replayed GenerateQR = %+v, want %+v", replayed, first)
+	}
+
+	if _, err := c.GenerateQR(ctx, generateRequest("PARTNER-1", "20000.00")); !errors.Is(err, ErrDuplicate) {
+		t.Errorf("GenerateQR reusing a partner reference error = %v, want ErrDuplicate", err)
+	}
+}
+
+func TestLegacySignature(t *testing.T) {
+	c := newGateway(t)
+	c.legacySignature = true
+
+	if _, err := c.GenerateQR(context.Background(), generateRequest("PARTNER-1", "15000.00")); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("GenerateQR with legacy signatures disabled error = %v, want ErrUnauthorized", err)
+	}
+}
+
+func TestRetries(t *testing.T) {
+	tests := []struct {
+		name      string
+		responses []int
+		wantCalls int
+		wantErr   error
+	}{
+		{"recovers from server errors", []int{503, 500, 200}, 3, nil},
+		{"recovers from rate limiting", []int{429, 200}, 2, nil},
+		{"gives up after max retries", []int{503, 503, 503, 503, 503}, 4, ErrServer},
+		{"does not retry client errors", []int{400, 200}, 1, ErrValidation},
+		{"does not retry not implemented", []int{501, 200}, 1, ErrServer},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				mu   sync.Mutex
+				keys []string
+			)
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mu.Lock()
+				call := len(keys)
+				keys = append(keys, r.Header.Get(IdempotencyHeader))
+				mu.Unlock()
+
+				w.WriteHeader(tt.responses[call])
+				if tt.responses[call] == http.StatusOK {
+					w.Write([]byte(`{"responseCode":"2004700","responseMessage":"Successful","referenceNo":"A1"}`))
+				}
+			}))
+			defer server.Close()
+
+			c := New(Config{BaseURL: server.URL, SecretKey: testSecret, RetryBackoff: time.Millisecond})
+			_, err := c.GenerateQR(context.Background(), generateRequest("PARTNER-1", "15000.00"))
+
+			if tt.wantErr == nil && err != nil || tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if len(keys) != tt.wantCalls {
+				t.Fatalf("server saw %d calls, want %d", len(keys), tt.wantCalls)
+			}
+			for _, key := range keys {
+				if key == "" || key != keys[0] {
+					t.Fatalf("idempotency keys %v, want one key reused by every attempt", keys)
+				}
+			}
+		})
+	}
+}
+
+func TestErrorKinds(t *testing.T) {
+	tests := []struct {
+		status int
+		body   string
+		want   error
+	}{
+		{400, `{"responseCode":"4004702","responseMessage":"Invalid Mandatory Field"}`, ErrValidation},
+		{401, `{"responseCode":"4015100","responseMessage":"Unauthorized"}`, ErrUnauthorized},
+		{403, `{"responseCode":"4035100","responseMessage":"Transaction Expired"}`, ErrExpired},
+		{404, `{"responseCode":"4045100","responseMessage":"Invalid Transaction Status"}`, ErrInvalidStatus},
+		{404, `{"responseCode":"4044801","responseMessage":"Transaction Not Found"}`, ErrNotFound},
+		{404, `{"responseCode":"4045113","responseMessage":"Invalid Amount"}`, ErrAmountMismatch},
+		{409, `{"responseCode":"4094701","responseMessage":"Duplicate partnerReferenceNo"}`, ErrDuplicate},
+		{404, `404 page not found`, ErrNotSupported},
+		{502, `bad gateway`, ErrServer},
+	}
+
+	for _, tt := range tests {
+		err := newError(tt.status, []byte(tt.body))
+		if !errors.Is(err, tt.want) {
+			t.Errorf("HTTP %d %s: %v does not match %v", tt.status, tt.body, err, tt.want)
+		}
+	}
+}
+
+func TestParseWebhook(t *testing.T) {
+	body := []byte(`{"eventId":7,"eventType":"TransactionPaid","aggregateId":"A1","occurredAt":"2025-01-02T03:04:05Z","data":{"reference_number":"A1","status":"SUCCESS"}}`)
+	request := func(body []byte, signature string) *http.Request {
+		r := httptest.NewRequest(http.MethodPost, "/webhook", bytes.NewReader(body))
+		r.Header.Set("X-Signature", signature)
+		return r
+	}
+
+	event, err := ParseWebhook(request(body, crypto.GenerateSignature(string(body), testSecret)), testSecret)
+	if err != nil {
+		t.Fatalf("ParseWebhook: %v", err)
+	}
+	transaction, err := event.Transaction()
+	if err != nil {
+		t.Fatalf("Transaction: %v", err)
+	}
+	if event.EventID != 7 || event.EventType != EventTransactionPaid || transaction.Status != "SUCCESS" {
+		t.Errorf("ParseWebhook = %+v with %+v", event, transaction)
+	}
+
+	tampered := bytes.Replace(body, []byte("SUCCESS"), []byte("FAILED"), 1)
+	if _, err := ParseWebhook(request(tampered, crypto.GenerateSignature(string(body), testSecret)), testSecret); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("tampered body error = %v, want ErrInvalidSignature", err)
+	}
+	if _, err := ParseWebhook(request(body, ""), testSecret); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("unsigned body error = %v, want ErrInvalidSignature", err)
+	}
+}
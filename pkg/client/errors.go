@@ -0,0 +1,143 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"payment-gateway-manjo/backend/pkg/snap"
+)
+
+// Sentinel errors for errors.Is. Every failed API call returns an *Error that
+// matches at most one of them, chosen from its SNAP response code.
+var (
+	ErrValidation     = errors.New("request failed validation")
+	ErrUnauthorized   = errors.New("signature rejected")
+	ErrNotFound       = errors.New("transaction not found")
+	ErrInvalidStatus  = errors.New("transaction status does not allow this")
+	ErrAmountMismatch = errors.New("amount does not match transaction")
+	ErrExpired        = errors.New("transaction expired")
+	ErrDuplicate      = errors.New("duplicate request")
+	ErrNotSupported   = errors.New("not supported by the gateway")
+	ErrRateLimited    = errors.New("rate limited")
+	ErrServer         = errors.New("gateway error")
+)
+
+// Error is a non-2xx answer from the gateway.
+type Error struct {
+	HTTPStatus      int
+	ResponseCode    string
+	ResponseMessage string
+	Detail          string
+	Fields          []snap.FieldError
+	RequestID       string
+}
+
+func newError(status int, payload []byte) *Error {
+	e := &Error{HTTPStatus: status}
+	var body snap.ErrorResponse
+	if err := json.Unmarshal(payload, &body); err == nil {
+		e.ResponseCode = body.ResponseCode
+		e.ResponseMessage = body.ResponseMessage
+		e.Detail = body.Error
+		e.Fields = body.Errors
+		e.RequestID = body.RequestID
+	} else {
+		e.Detail = strings.TrimSpace(string(payload))
+	}
+	return e
+}
+
+func (e *Error) Error() string {
+	var b strings.Builder
+	if e.ResponseCode != "" {
+		fmt.Fprintf(&b, "gateway responded %s %s", e.ResponseCode, e.ResponseMessage)
+	} else {
+		fmt.Fprintf(&b, "gateway responded HTTP %d", e.HTTPStatus)
+	}
+	if e.Detail != "" {
+		fmt.Fprintf(&b, ": %s", e.Detail)
+	}
+	for _, field := range e.Fields {
+		fmt.Fprintf(&b, "; %s: %s", field.Field, field.Message)
+	}
+	if e.RequestID != "" {
+		fmt.Fprintf(&b, " (request %s)", e.RequestID)
+	}
+	return b.String()
+}
+
+// Is matches the sentinel errors above.
+func (e *Error) Is(target error) bool {
+	return e.kind() == target
+}
+
+// Temporary reports a failure that the same request may not hit again.
+func (e *Error) Temporary() bool {
+	switch e.kind() {
+	case ErrRateLimited:
+		return true
+	case ErrServer:
+		return e.HTTPStatus != http.StatusNotImplemented
+	}
+	return false
+}
+
+// kind maps the response to a sentinel. A response without a SNAP code, such
+// as the router's 404 for an endpoint this gateway does not serve, is judged
+// by its HTTP status alone.
+func (e *Error) kind() error {
+	if snapCase, ok := e.snapCase(); ok {
+		switch snapCase {
+		case snap.CaseBadRequest, snap.CaseInvalidFieldFormat, snap.CaseMissingMandatoryField:
+			return ErrValidation
+		case snap.CaseUnauthorized, snap.CaseInvalidToken:
+			return ErrUnauthorized
+		case snap.CaseTransactionNotFound:
+			return ErrNotFound
+		case snap.CaseInvalidTransactionStatus, snap.CaseTransactionCancelled, snap.CasePaidBill:
+			return ErrInvalidStatus
+		case snap.CaseInvalidAmount:
+			return ErrAmountMismatch
+		case snap.CaseTransactionExpired:
+			return ErrExpired
+		case snap.CaseDuplicateExternalID, snap.CaseDuplicatePartnerReference:
+			return ErrDuplicate
+		case snap.CaseNotSupported, snap.CaseFeatureNotAllowed:
+			return ErrNotSupported
+		case snap.CaseTooManyRequests:
+			return ErrRateLimited
+		}
+	}
+
+	switch {
+	case e.HTTPStatus == http.StatusTooManyRequests:
+		return ErrRateLimited
+	case e.HTTPStatus >= 500:
+		return ErrServer
+	case e.ResponseCode == "" && (e.HTTPStatus == http.StatusNotFound || e.HTTPStatus == http.StatusMethodNotAllowed):
+		return ErrNotSupported
+	case e.HTTPStatus == http.StatusBadRequest:
+		return ErrValidation
+	case e.HTTPStatus == http.StatusUnauthorized:
+		return ErrUnauthorized
+	}
+	return nil
+}
+
+// snapCase finds the catalogue entry for the response code. The service part
+// in the middle is ignored; the case is the same for every service.
+func (e *Error) snapCase() (snap.Case, bool) {
+	code := e.ResponseCode
+	if len(code) != 7 {
+		return snap.Case{}, false
+	}
+	for _, snapCase := range snap.Catalog() {
+		if fmt.Sprintf("%03d", snapCase.HTTPStatus) == code[:3] && snapCase.Code == code[5:] {
+			return snapCase, true
+		}
+	}
+	return snap.Case{}, false
+}
@@ -0,0 +1,211 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"payment-gateway-manjo/backend/pkg/crypto"
+	"payment-gateway-manjo/backend/pkg/snap"
+)
+
+type Amount struct {
+	Value    string `json:"value"`
+	Currency string `json:"currency"`
+}
+
+type GenerateQRRequest struct {
+	MerchantID         string `json:"merchantId"`
+	PartnerReferenceNo string `json:"partnerReferenceNo"`
+	Amount             Amount `json:"amount"`
+}
+
+type GenerateQRResponse struct {
+	ResponseCode       string `json:"responseCode"`
+	ResponseMessage    string `json:"responseMessage"`
+	ReferenceNo        string `json:"referenceNo"`
+	PartnerReferenceNo string `json:"partnerReferenceNo"`
+	QRContent          string `json:"qrContent"`
+}
+
+type Transaction struct {
+	ID                     uint       `json:"id"`
+	MerchantID             string     `json:"merchant_id"`
+	Amount                 float64    `json:"amount"`
+	Currency               string     `json:"currency"`
+	PartnerReferenceNumber string     `json:"partner_reference_number"`
+	ReferenceNumber        string     `json:"reference_number"`
+	Status                 string     `json:"status"`
+	TransactionDate        time.Time  `json:"transaction_date"`
+	PaidDate               *time.Time `json:"paid_date,omitempty"`
+	ReceivedAt             *time.Time `json:"received_at,omitempty"`
+	PaidTimeFlag           string     `json:"paid_time_flag,omitempty"`
+	QRContent              string     `json:"qr_content,omitempty"`
+	CreatedAt              time.Time  `json:"created_at"`
+	UpdatedAt              time.Time  `json:"updated_at"`
+}
+
+type TransactionEvent struct {
+	ID              uint      `json:"id"`
+	TransactionID   uint      `json:"transaction_id"`
+	ReferenceNumber string    `json:"reference_number"`
+	OldStatus       string    `json:"old_status"`
+	NewStatus       string    `json:"new_status"`
+	Source          string    `json:"source"`
+	PayloadHash     string    `json:"payload_hash,omitempty"`
+	Actor           string    `json:"actor"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+// ListFilter narrows ListTransactions; empty fields do not filter.
+type ListFilter struct {
+	MerchantID         string
+	PartnerReferenceNo string
+	ReferenceNo        string
+	Status             string
+}
+
+type CancelRequest struct {
+	OriginalReferenceNo        string `json:"originalReferenceNo"`
+	OriginalPartnerReferenceNo string `json:"originalPartnerReferenceNo"`
+	MerchantID                 string `json:"merchantId"`
+	Reason                     string `json:"reason,omitempty"`
+}
+
+type CancelResponse struct {
+	ResponseCode               string `json:"responseCode"`
+	ResponseMessage            string `json:"responseMessage"`
+	OriginalReferenceNo        string `json:"originalReferenceNo"`
+	OriginalPartnerReferenceNo string `json:"originalPartnerReferenceNo"`
+	CancelTime                 string `json:"cancelTime"`
+}
+
+type RefundRequest struct {
+	OriginalReferenceNo        string `json:"originalReferenceNo"`
+	OriginalPartnerReferenceNo string `json:"originalPartnerReferenceNo"`
+	PartnerRefundNo            string `json:"partnerRefundNo"`
+	MerchantID                 string `json:"merchantId"`
+	RefundAmount               Amount `json:"refundAmount"`
+	Reason                     string `json:"reason,omitempty"`
+}
+
+type RefundResponse struct {
+	ResponseCode               string `json:"responseCode"`
+	ResponseMessage            string `json:"responseMessage"`
+	OriginalReferenceNo        string `json:"originalReferenceNo"`
+	OriginalPartnerReferenceNo string `json:"originalPartnerReferenceNo"`
+	RefundNo                   string `json:"refundNo"`
+	PartnerRefundNo            string `json:"partnerRefundNo"`
+	RefundAmount               Amount `json:"refundAmount"`
+	RefundTime                 string `json:"refundTime"`
+}
+
+type envelope[T any] struct {
+	ResponseCode    string `json:"responseCode"`
+	ResponseMessage string `json:"responseMessage"`
+	Data            T      `json:"data"`
+}
+
+// GenerateQR is safe to retry: the gateway refuses a second transaction for
+// the same partnerReferenceNo, and a duplicate answer for a request identical
+// to the stored transaction, e.g. after a response was lost, is resolved into
+// that transaction's QR.
+func (c *Client) GenerateQR(ctx context.Context, request GenerateQRRequest) (*GenerateQRResponse, error) {
+	req := call{method: http.MethodPost, path: "/api/v1/qr/generate", body: request}
+	if c.legacySignature {
+		req.signature = crypto.GenerateQRSignatureString(request.MerchantID, request.Amount.Value, request.PartnerReferenceNo)
+	}
+
+	var out GenerateQRResponse
+	err := c.do(ctx, req, &out)
+	if errors.Is(err, ErrDuplicate) {
+		if existing, ok := c.replayed(ctx, request); ok {
+			return existing, nil
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *Client) replayed(ctx context.Context, request GenerateQRRequest) (*GenerateQRResponse, bool) {
+	transaction, err := c.Query(ctx, "", request.PartnerReferenceNo)
+	if err != nil {
+		return nil, false
+	}
+	if transaction.MerchantID != request.MerchantID ||
+		transaction.Currency != request.Amount.Currency ||
+		fmt.Sprintf("%.2f", transaction.Amount) != request.Amount.Value {
+		return nil, false
+	}
+	return &GenerateQRResponse{
+		ResponseCode:       snap.Code(snap.ServiceGenerateQR, snap.CaseSuccessful),
+		ResponseMessage:    snap.CaseSuccessful.Message,
+		ReferenceNo:        transaction.ReferenceNumber,
+		PartnerReferenceNo: transaction.PartnerReferenceNumber,
+		QRContent:          transaction.QRContent,
+	}, true
+}
+
+// Query returns one transaction by the gateway's or the partner's reference;
+// pass either.
+func (c *Client) Query(ctx context.Context, referenceNo, partnerReferenceNo string) (*Transaction, error) {
+	if referenceNo == "" && partnerReferenceNo == "" {
+		return nil, fmt.Errorf("%w: referenceNo or partnerReferenceNo is required", ErrValidation)
+	}
+	transactions, err := c.ListTransactions(ctx, ListFilter{ReferenceNo: referenceNo, PartnerReferenceNo: partnerReferenceNo})
+	if err != nil {
+		return nil, err
+	}
+	if len(transactions) == 0 {
+		return nil, ErrNotFound
+	}
+	return &transactions[0], nil
+}
+
+func (c *Client) ListTransactions(ctx context.Context, filter ListFilter) ([]Transaction, error) {
+	query := url.Values{}
+	for key, value := range map[string]string{
+		"merchantId":         filter.MerchantID,
+		"partnerReferenceNo": filter.PartnerReferenceNo,
+		"referenceNo":        filter.ReferenceNo,
+		"status":             filter.Status,
+	} {
+		if value != "" {
+			query.Set(key, value)
+		}
+	}
+
+	var out envelope[[]Transaction]
+	if err := c.do(ctx, call{method: http.MethodGet, path: "/api/v1/transactions", query: query}, &out); err != nil {
+		return nil, err
+	}
+	return out.Data, nil
+}
+
+// History returns every status change of a transaction, oldest first.
+func (c *Client) History(ctx context.Context, referenceNo string) ([]TransactionEvent, error) {
+	var out envelope[[]TransactionEvent]
+	path := "/api/v1/transactions/" + url.PathEscape(referenceNo) + "/history"
+	if err := c.do(ctx, call{method: http.MethodGet, path: path}, &out); err != nil {
+		return nil, err
+	}
+	return out.Data, nil
+}
+
+// Cancel voids an unpaid QR. The gateway does not serve cancellation yet, so
+// this returns ErrNotSupported without calling it.
+func (c *Client) Cancel(ctx context.Context, request CancelRequest) (*CancelResponse, error) {
+	return nil, ErrNotSupported
+}
+
+// Refund returns all or part of a paid amount; PartnerRefundNo makes retries
+// safe. The gateway does not serve refunds yet, so this returns
+// ErrNotSupported without calling it.
+func (c *Client) Refund(ctx context.Context, request RefundRequest) (*RefundResponse, error) {
+	return nil, ErrNotSupported
+}
@@ -0,0 +1,76 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"payment-gateway-manjo/backend/pkg/crypto"
+)
+
+// Event types the gateway's webhook sink delivers.
+const (
+	EventTransactionCreated = "TransactionCreated"
+	EventTransactionPaid    = "TransactionPaid"
+	EventTransactionFailed  = "TransactionFailed"
+	EventTransactionExpired = "TransactionExpired"
+)
+
+// maxWebhookBytes bounds how much of a webhook request ParseWebhook reads.
+const maxWebhookBytes = 1 << 20
+
+var ErrInvalidSignature = errors.New("invalid webhook signature")
+
+// WebhookEvent is the body the gateway posts to webhook receivers. Data holds
+// the transaction as it was when the event was recorded and decodes into
+// Transaction.
+type WebhookEvent struct {
+	EventID     uint            `json:"eventId"`
+	EventType   string          `json:"eventType"`
+	AggregateID string          `json:"aggregateId"`
+	OccurredAt  time.Time       `json:"occurredAt"`
+	Data        json.RawMessage `json:"data"`
+}
+
+// Transaction decodes the event's data.
+func (e *WebhookEvent) Transaction() (*Transaction, error) {
+	var transaction Transaction
+	if err := json.Unmarshal(e.Data, &transaction); err != nil {
+		return nil, fmt.Errorf("failed to decode event data: %w", err)
+	}
+	return &transaction, nil
+}
+
+// VerifyWebhookSignature checks X-Signature against the exact body bytes
+// received, before anything decodes them.
+func VerifyWebhookSignature(body []byte, signature, secretKey string) error {
+	if signature == "" || !crypto.ValidateSignature(string(body), signature, secretKey) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// ParseWebhook reads, verifies and decodes a webhook request. Receivers should
+// answer 2xx only once the event is stored: the gateway redelivers anything
+// else, so EventID may arrive more than once.
+func ParseWebhook(r *http.Request, secretKey string) (*WebhookEvent, error) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxWebhookBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read webhook body: %w", err)
+	}
+	if len(body) > maxWebhookBytes {
+		return nil, fmt.Errorf("webhook body exceeds %d bytes", maxWebhookBytes)
+	}
+	if err := VerifyWebhookSignature(body, r.Header.Get("X-Signature"), secretKey); err != nil {
+		return nil, err
+	}
+
+	var event WebhookEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		return nil, fmt.Errorf("failed to decode webhook body: %w", err)
+	}
+	return &event, nil
+}
@@ -5,7 +5,7 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
-	"strings"	
+	"strings"
 )
 
 func GenerateSignature(data string, secretKey string) string {
@@ -29,4 +29,29 @@ func GenerateQRSignatureString(merchantID, amount, partnerRefNo string) string {
 
 func GeneratePaymentSignatureString(referenceNo, amount, status string) string {
 	return fmt.Sprintf("%s|%s|%s", referenceNo, amount, status)
-}
\ No newline at end of file
+}
+
+// GenerateFeedSignatureString is what a merchant signs to open its live feed;
+// the timestamp bounds how long a signed URL can be reused.
+func GenerateFeedSignatureString(merchantID, timestamp string) string {
+	return fmt.Sprintf("%s|%s", merchantID, timestamp)
+}
+
+// GenerateStreamSignatureString is what a merchant signs to open the status
+// stream of one of its transactions.
+func GenerateStreamSignatureString(referenceNo, timestamp string) string {
+	return fmt.Sprintf("%s|%s", referenceNo, timestamp)
+}
+
+// DeriveMerchantKey returns the key a merchant signs its feed and stream
+// requests with:
+// an HMAC of its ID under the gateway's feed secret. The gateway needs no key
+// table, and one merchant's key opens no other merchant's feed.
+func DeriveMerchantKey(merchantID, feedSecret string) string {
+	return GenerateSignature("merchant-key|"+merchantID, feedSecret)
+}
+
+func HashPayload(payload []byte) string {
+	sum := sha256.Sum256(payload)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,52 @@
+// Package qris builds and reads the QR content the gateway hands to merchants.
+// The generator and the acquirer simulator share it so the two cannot drift.
+package qris
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+// Currency is the only currency the QR content encodes (tag 53, ISO 4217 360).
+const Currency = "IDR"
+
+const (
+	header  = "00020101021226620015ID.CO.MANJO.WWW01189360085801751859910210"
+	body    = "0303UMI51530014ID.CO.QRIS.WWW0215ID102106515192304121.0.21.09.255204481653033605502015802ID5904OLDI6013JAKARTA BARAT61051147062454"
+	refTag  = "62460525"
+	trailer = "07031110806ASPI663040FAD"
+)
+
+var contentPattern = regexp.MustCompile(
+	"^" + regexp.QuoteMeta(header) + "(.+?)" + regexp.QuoteMeta(body) +
+		`(\d{2})(\d+\.\d{2})` + regexp.QuoteMeta(refTag) + "(.+)" + regexp.QuoteMeta(trailer) + "$",
+)
+
+var ErrInvalidContent = errors.New("invalid QR content")
+
+// Payload is what an acquirer reads from a scanned QR.
+type Payload struct {
+	MerchantID  string
+	ReferenceNo string
+	// Amount keeps the two-decimal form it is encoded and signed in.
+	Amount string
+}
+
+func Encode(merchantID, referenceNo string, amount float64) string {
+	amountStr := fmt.Sprintf("%.2f", amount)
+	return fmt.Sprintf("%s%s%s%02d%s%s%s%s", header, merchantID, body, len(amountStr), amountStr, refTag, referenceNo, trailer)
+}
+
+func Decode(content string) (Payload, error) {
+	match := contentPattern.FindStringSubmatch(content)
+	if match == nil {
+		return Payload{}, ErrInvalidContent
+	}
+	length, err := strconv.Atoi(match[2])
+	if err != nil || length != len(match[3]) {
+		return Payload{}, fmt.Errorf("%w: amount length %s does not match %q", ErrInvalidContent, match[2], match[3])
+	}
+	return Payload{MerchantID: match[1], ReferenceNo: match[4], Amount: match[3]}, nil
+}
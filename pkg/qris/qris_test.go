@@ -0,0 +1,47 @@
+package qris
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestEncodeDecode(t *testing.T) {
+	tests := []struct {
+		merchantID  string
+		referenceNo string
+		amount      float64
+		want        string
+	}{
+		{"MERCHANT-1", "A1b2c3d4e5", 15000, "15000.00"},
+		{"M", "Aabcdef012", 0.5, "0.50"},
+		{"MERCHANT_WITH_A_LONG_ID", "A0000000000", 123456789.99, "123456789.99"},
+	}
+
+	for _, tt := range tests {
+		content := Encode(tt.merchantID, tt.referenceNo, tt.amount)
+		got, err := Decode(content)
+		if err != nil {
+			t.Fatalf("Decode(%q): %v", content, err)
+		}
+		want := Payload{MerchantID: tt.merchantID, ReferenceNo: tt.referenceNo, Amount: tt.want}
+		if got != want {
+			t.Errorf("Decode(Encode(%q, %q, %v)) = %+v, want %+v", tt.merchantID, tt.referenceNo, tt.amount, got, want)
+		}
+	}
+}
+
+func TestDecodeRejects(t *testing.T) {
+	valid := Encode("MERCHANT-1", "A1b2c3d4e5", 15000)
+	tests := map[string]string{
+		"empty":           "",
+		"truncated":       valid[:len(valid)-4],
+		"not a QR":        "hello",
+		"length mismatch": Encode("MERCHANT-1", "A1b2c3d4e5", 15000)[:len(header)+len("MERCHANT-1")+len(body)] + "09" + "15000.00" + refTag + "A1b2c3d4e5" + trailer,
+	}
+
+	for name, content := range tests {
+		if _, err := Decode(content); !errors.Is(err, ErrInvalidContent) {
+			t.Errorf("%s: Decode() error = %v, want ErrInvalidContent", name, err)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package response
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"payment-gateway-manjo/backend/internal/domain/domainerr"
+	"payment-gateway-manjo/backend/pkg/snap"
+
+	"github.com/gin-gonic/gin"
+)
+
+type errorMapping struct {
+	kind     error
+	snapCase snap.Case
+}
+
+var errorMappings = []errorMapping{
+	{domainerr.ErrNotFound, snap.CaseTransactionNotFound},
+	{domainerr.ErrAmountMismatch, snap.CaseInvalidAmount},
+	{domainerr.ErrInvalidState, snap.CaseInvalidTransactionStatus},
+	{domainerr.ErrExpired, snap.CaseTransactionExpired},
+	{domainerr.ErrDuplicate, snap.CaseDuplicatePartnerReference},
+	{domainerr.ErrConflict, snap.CaseDuplicateExternalID},
+	{domainerr.ErrInvalidInput, snap.CaseBadRequest},
+}
+
+// CaseFor returns the SNAP case used to answer err.
+func CaseFor(err error) snap.Case {
+	for _, mapping := range errorMappings {
+		if errors.Is(err, mapping.kind) {
+			return mapping.snapCase
+		}
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return snap.CaseTimeout
+	}
+	if errors.Is(err, context.Canceled) {
+		return snap.CaseClientClosed
+	}
+	return snap.CaseGeneralError
+}
+
+// FromError writes the error response for err on behalf of service. Domain
+// errors expose their client-safe message; anything else is logged and
+// answered with a generic body so database and driver details never reach
+// the client.
+func FromError(c *gin.Context, service snap.ServiceCode, err error) {
+	snapCase := CaseFor(err)
+	switch snapCase {
+	case snap.CaseGeneralError:
+		slog.ErrorContext(c.Request.Context(), "unhandled error", "error", err)
+		Error(c, service, snapCase, "")
+	case snap.CaseTimeout:
+		Error(c, service, snapCase, "Request timed out")
+	case snap.CaseClientClosed:
+		slog.DebugContext(c.Request.Context(), "client closed request", "error", err)
+		Error(c, service, snapCase, "")
+	default:
+		Error(c, service, snapCase, domainerr.Message(err))
+	}
+}
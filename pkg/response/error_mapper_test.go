@@ -0,0 +1,64 @@
+package response
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"payment-gateway-manjo/backend/internal/domain/domainerr"
+	"payment-gateway-manjo/backend/pkg/snap"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestCaseFor(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want snap.Case
+	}{
+		{"not found", domainerr.New(domainerr.ErrNotFound, "missing"), snap.CaseTransactionNotFound},
+		{"amount mismatch", domainerr.New(domainerr.ErrAmountMismatch, "mismatch"), snap.CaseInvalidAmount},
+		{"invalid state", domainerr.New(domainerr.ErrInvalidState, "final"), snap.CaseInvalidTransactionStatus},
+		{"expired", domainerr.New(domainerr.ErrExpired, "expired"), snap.CaseTransactionExpired},
+		{"duplicate", domainerr.New(domainerr.ErrDuplicate, "duplicate"), snap.CaseDuplicatePartnerReference},
+		{"conflict", domainerr.New(domainerr.ErrConflict, "conflict"), snap.CaseDuplicateExternalID},
+		{"invalid input", domainerr.New(domainerr.ErrInvalidInput, "bad"), snap.CaseBadRequest},
+		{"deadline", fmt.Errorf("failed to query: %w", context.DeadlineExceeded), snap.CaseTimeout},
+		{"client gone", fmt.Errorf("failed to query: %w", context.Canceled), snap.CaseClientClosed},
+		{"unknown", fmt.Errorf("connection reset"), snap.CaseGeneralError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CaseFor(tt.err); got != tt.want {
+				t.Errorf("CaseFor() = %q, want %q", got.Message, tt.want.Message)
+			}
+		})
+	}
+}
+
+func TestFromErrorClientClosed(t *testing.T) {
+	var logs bytes.Buffer
+	previous := slog.Default()
+	slog.SetDefault(slog.New(slog.NewJSONHandler(&logs, &slog.HandlerOptions{Level: slog.LevelInfo})))
+	t.Cleanup(func() { slog.SetDefault(previous) })
+
+	gin.SetMode(gin.TestMode)
+	recorder := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(recorder)
+	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+
+	FromError(c, snap.ServiceQuery, fmt.Errorf("failed to query: %w", context.Canceled))
+
+	if recorder.Code != snap.StatusClientClosedRequest {
+		t.Errorf("status = %d, want %d", recorder.Code, snap.StatusClientClosedRequest)
+	}
+	if logs.Len() != 0 {
+		t.Errorf("a client disconnect was logged at info or above: %s", logs.String())
+	}
+}
@@ -1,40 +1,34 @@
 package response
 
-import "github.com/gin-gonic/gin"
+import (
+	"payment-gateway-manjo/backend/pkg/requestid"
+	"payment-gateway-manjo/backend/pkg/snap"
 
-type Response struct {
-	ResponseCode    string      `json:"responseCode"`
-	ResponseMessage string      `json:"responseMessage"`
-	Data            interface{} `json:"data,omitempty"`
-}
-
-type ErrorResponse struct {
-	ResponseCode    string `json:"responseCode"`
-	ResponseMessage string `json:"responseMessage"`
-	Error           string `json:"error,omitempty"`
-}
+	"github.com/gin-gonic/gin"
+)
 
-func Success(c *gin.Context, code int, responseCode, message string, data interface{}) {
-	c.JSON(code, Response{
-		ResponseCode:    responseCode,
-		ResponseMessage: message,
+func Success(c *gin.Context, service snap.ServiceCode, data interface{}) {
+	c.JSON(snap.CaseSuccessful.HTTPStatus, snap.Response{
+		ResponseCode:    snap.Code(service, snap.CaseSuccessful),
+		ResponseMessage: snap.CaseSuccessful.Message,
 		Data:            data,
 	})
 }
 
-func Error(c *gin.Context, code int, responseCode, message, errorDetail string) {
-	c.JSON(code, ErrorResponse{
-		ResponseCode:    responseCode,
-		ResponseMessage: message,
+func Error(c *gin.Context, service snap.ServiceCode, snapCase snap.Case, errorDetail string) {
+	c.JSON(snapCase.HTTPStatus, snap.ErrorResponse{
+		ResponseCode:    snap.Code(service, snapCase),
+		ResponseMessage: snapCase.Message,
 		Error:           errorDetail,
+		RequestID:       requestid.FromContext(c.Request.Context()),
 	})
 }
 
-const (
-	CodeSuccess            = "2004700" 
-	CodePaymentSuccess     = "2005100"
-	CodeBadRequest         = "4000000"
-	CodeUnauthorized       = "4010000"
-	CodeNotFound           = "4040000"
-	CodeInternalError      = "5000000"
-)
\ No newline at end of file
+func ValidationError(c *gin.Context, service snap.ServiceCode, snapCase snap.Case, fieldErrors []snap.FieldError) {
+	c.JSON(snapCase.HTTPStatus, snap.ErrorResponse{
+		ResponseCode:    snap.Code(service, snapCase),
+		ResponseMessage: snapCase.Message,
+		Errors:          fieldErrors,
+		RequestID:       requestid.FromContext(c.Request.Context()),
+	})
+}
@@ -0,0 +1,126 @@
+// Package snap holds the SNAP BI wire format shared by the gateway and its
+// client SDK: response codes, their cases and the response bodies. It has no
+// dependencies outside the standard library so the SDK stays small.
+package snap
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// ServiceCode is the two-digit SNAP service identifier embedded in every
+// response code.
+type ServiceCode string
+
+const (
+	ServiceGeneric    ServiceCode = "00"
+	ServiceGenerateQR ServiceCode = "47"
+	ServiceQuery      ServiceCode = "48"
+	ServiceNotify     ServiceCode = "51"
+	ServiceCancel     ServiceCode = "77"
+	ServiceRefund     ServiceCode = "78"
+)
+
+// Case is a SNAP response case: the HTTP status, the two-digit case code
+// and the standard response message.
+type Case struct {
+	HTTPStatus int
+	Code       string
+	Message    string
+}
+
+var (
+	CaseSuccessful                = Case{http.StatusOK, "00", "Successful"}
+	CaseInProgress                = Case{http.StatusAccepted, "00", "Request In Progress"}
+	CaseBadRequest                = Case{http.StatusBadRequest, "00", "Bad Request"}
+	CaseInvalidFieldFormat        = Case{http.StatusBadRequest, "01", "Invalid Field Format"}
+	CaseMissingMandatoryField     = Case{http.StatusBadRequest, "02", "Invalid Mandatory Field"}
+	CaseUnauthorized              = Case{http.StatusUnauthorized, "00", "Unauthorized"}
+	CaseInvalidToken              = Case{http.StatusUnauthorized, "01", "Invalid Token (B2B)"}
+	CaseTransactionExpired        = Case{http.StatusForbidden, "00", "Transaction Expired"}
+	CaseFeatureNotAllowed         = Case{http.StatusForbidden, "01", "Feature Not Allowed"}
+	CaseExceedsAmountLimit        = Case{http.StatusForbidden, "02", "Exceeds Transaction Amount Limit"}
+	CaseSuspectedFraud            = Case{http.StatusForbidden, "03", "Suspected Fraud"}
+	CaseDoNotHonor                = Case{http.StatusForbidden, "05", "Do Not Honor"}
+	CaseInsufficientFunds         = Case{http.StatusForbidden, "14", "Insufficient Funds"}
+	CaseTransactionNotPermitted   = Case{http.StatusForbidden, "15", "Transaction Not Permitted"}
+	CaseMerchantBlacklisted       = Case{http.StatusForbidden, "19", "Merchant Blacklisted"}
+	CaseInvalidTransactionStatus  = Case{http.StatusNotFound, "00", "Invalid Transaction Status"}
+	CaseTransactionNotFound       = Case{http.StatusNotFound, "01", "Transaction Not Found"}
+	CaseTransactionCancelled      = Case{http.StatusNotFound, "04", "Transaction Cancelled"}
+	CaseInvalidMerchant           = Case{http.StatusNotFound, "08", "Invalid Merchant"}
+	CaseInvalidAmount             = Case{http.StatusNotFound, "13", "Invalid Amount"}
+	CasePaidBill                  = Case{http.StatusNotFound, "14", "Paid Bill"}
+	CaseInconsistentRequest       = Case{http.StatusNotFound, "18", "Inconsistent Request"}
+	CaseNotSupported              = Case{http.StatusMethodNotAllowed, "00", "Requested Function Is Not Supported"}
+	CaseDuplicateExternalID       = Case{http.StatusConflict, "00", "Conflict"}
+	CaseDuplicatePartnerReference = Case{http.StatusConflict, "01", "Duplicate partnerReferenceNo"}
+	CaseTooManyRequests           = Case{http.StatusTooManyRequests, "00", "Too Many Requests"}
+	CaseGeneralError              = Case{http.StatusInternalServerError, "00", "General Error"}
+	CaseInternalServerError       = Case{http.StatusInternalServerError, "01", "Internal Server Error"}
+	CaseExternalServerError       = Case{http.StatusInternalServerError, "02", "External Server Error"}
+	CaseTimeout                   = Case{http.StatusGatewayTimeout, "00", "Timeout"}
+)
+
+// StatusClientClosedRequest is the non-standard status nginx uses for a
+// request whose client went away before the answer was ready.
+const StatusClientClosedRequest = 499
+
+// CaseClientClosed answers a request the client cancelled. Nobody reads the
+// body, so it is not part of the catalog; it only keeps disconnects out of
+// the 5xx counts.
+var CaseClientClosed = Case{StatusClientClosedRequest, "00", "Client Closed Request"}
+
+// Catalog lists every case this gateway can answer with.
+func Catalog() []Case {
+	return []Case{
+		CaseSuccessful,
+		CaseInProgress,
+		CaseBadRequest,
+		CaseInvalidFieldFormat,
+		CaseMissingMandatoryField,
+		CaseUnauthorized,
+		CaseInvalidToken,
+		CaseTransactionExpired,
+		CaseFeatureNotAllowed,
+		CaseExceedsAmountLimit,
+		CaseSuspectedFraud,
+		CaseDoNotHonor,
+		CaseInsufficientFunds,
+		CaseTransactionNotPermitted,
+		CaseMerchantBlacklisted,
+		CaseInvalidTransactionStatus,
+		CaseTransactionNotFound,
+		CaseTransactionCancelled,
+		CaseInvalidMerchant,
+		CaseInvalidAmount,
+		CasePaidBill,
+		CaseInconsistentRequest,
+		CaseNotSupported,
+		CaseDuplicateExternalID,
+		CaseDuplicatePartnerReference,
+		CaseTooManyRequests,
+		CaseGeneralError,
+		CaseInternalServerError,
+		CaseExternalServerError,
+		CaseTimeout,
+	}
+}
+
+// Services lists every SNAP service code the gateway exposes.
+func Services() []ServiceCode {
+	return []ServiceCode{
+		ServiceGeneric,
+		ServiceGenerateQR,
+		ServiceQuery,
+		ServiceNotify,
+		ServiceCancel,
+		ServiceRefund,
+	}
+}
+
+// Code builds the seven-digit SNAP response code: HTTP status, service code
+// and case code, e.g. 4044701 for "Transaction Not Found" on Generate QR.
+func Code(service ServiceCode, snapCase Case) string {
+	return fmt.Sprintf("%03d%s%s", snapCase.HTTPStatus, service, snapCase.Code)
+}
@@ -0,0 +1,97 @@
+package snap
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"testing"
+)
+
+func TestCodeCatalog(t *testing.T) {
+	tests := []struct {
+		snapCase Case
+		service  ServiceCode
+		want     string
+	}{
+		{CaseSuccessful, ServiceGenerateQR, "2004700"},
+		{CaseSuccessful, ServiceNotify, "2005100"},
+		{CaseSuccessful, ServiceQuery, "2004800"},
+		{CaseSuccessful, ServiceCancel, "2007700"},
+		{CaseSuccessful, ServiceRefund, "2007800"},
+		{CaseInProgress, ServiceRefund, "2027800"},
+		{CaseBadRequest, ServiceGenerateQR, "4004700"},
+		{CaseInvalidFieldFormat, ServiceGenerateQR, "4004701"},
+		{CaseMissingMandatoryField, ServiceNotify, "4005102"},
+		{CaseUnauthorized, ServiceNotify, "4015100"},
+		{CaseInvalidToken, ServiceQuery, "4014801"},
+		{CaseTransactionExpired, ServiceNotify, "4035100"},
+		{CaseFeatureNotAllowed, ServiceCancel, "4037701"},
+		{CaseExceedsAmountLimit, ServiceGenerateQR, "4034702"},
+		{CaseSuspectedFraud, ServiceNotify, "4035103"},
+		{CaseDoNotHonor, ServiceNotify, "4035105"},
+		{CaseInsufficientFunds, ServiceRefund, "4037814"},
+		{CaseTransactionNotPermitted, ServiceCancel, "4037715"},
+		{CaseMerchantBlacklisted, ServiceGenerateQR, "4034719"},
+		{CaseInvalidTransactionStatus, ServiceNotify, "4045100"},
+		{CaseTransactionNotFound, ServiceQuery, "4044801"},
+		{CaseTransactionCancelled, ServiceCancel, "4047704"},
+		{CaseInvalidMerchant, ServiceGenerateQR, "4044708"},
+		{CaseInvalidAmount, ServiceNotify, "4045113"},
+		{CasePaidBill, ServiceCancel, "4047714"},
+		{CaseInconsistentRequest, ServiceRefund, "4047818"},
+		{CaseNotSupported, ServiceGeneric, "4050000"},
+		{CaseDuplicateExternalID, ServiceGenerateQR, "4094700"},
+		{CaseDuplicatePartnerReference, ServiceGenerateQR, "4094701"},
+		{CaseTooManyRequests, ServiceQuery, "4294800"},
+		{CaseGeneralError, ServiceGenerateQR, "5004700"},
+		{CaseInternalServerError, ServiceGeneric, "5000001"},
+		{CaseExternalServerError, ServiceRefund, "5007802"},
+		{CaseTimeout, ServiceNotify, "5045100"},
+	}
+
+	covered := make(map[Case]bool)
+	for _, tt := range tests {
+		covered[tt.snapCase] = true
+		t.Run(tt.snapCase.Message+"/"+string(tt.service), func(t *testing.T) {
+			if got := Code(tt.service, tt.snapCase); got != tt.want {
+				t.Errorf("Code(%s, %q) = %s, want %s", tt.service, tt.snapCase.Message, got, tt.want)
+			}
+		})
+	}
+
+	for _, snapCase := range Catalog() {
+		if !covered[snapCase] {
+			t.Errorf("case %q has no expected code in the catalog table", snapCase.Message)
+		}
+	}
+}
+
+func TestCatalogIsWellFormed(t *testing.T) {
+	codePattern := regexp.MustCompile(`^[1-5]\d{2}\d{2}\d{2}$`)
+
+	for _, service := range Services() {
+		seen := make(map[string]string)
+		for _, snapCase := range Catalog() {
+			code := Code(service, snapCase)
+			if !codePattern.MatchString(code) {
+				t.Errorf("code %s for %q is not seven digits", code, snapCase.Message)
+			}
+			if prefix := fmt.Sprintf("%03d", snapCase.HTTPStatus); code[:3] != prefix {
+				t.Errorf("code %s does not start with HTTP status %s", code, prefix)
+			}
+			if code[3:5] != string(service) {
+				t.Errorf("code %s does not carry service code %s", code, service)
+			}
+			if http.StatusText(snapCase.HTTPStatus) == "" {
+				t.Errorf("case %q uses unknown HTTP status %d", snapCase.Message, snapCase.HTTPStatus)
+			}
+			if snapCase.Message == "" {
+				t.Errorf("code %s has no message", code)
+			}
+			if other, ok := seen[code]; ok {
+				t.Errorf("code %s is shared by %q and %q", code, other, snapCase.Message)
+			}
+			seen[code] = snapCase.Message
+		}
+	}
+}
@@ -0,0 +1,24 @@
+package snap
+
+// Response is the body of a successful call.
+type Response struct {
+	ResponseCode    string      `json:"responseCode"`
+	ResponseMessage string      `json:"responseMessage"`
+	Data            interface{} `json:"data,omitempty"`
+}
+
+// ErrorResponse is the body of a failed call.
+type ErrorResponse struct {
+	ResponseCode    string       `json:"responseCode"`
+	ResponseMessage string       `json:"responseMessage"`
+	Error           string       `json:"error,omitempty"`
+	Errors          []FieldError `json:"errors,omitempty"`
+	RequestID       string       `json:"requestId,omitempty"`
+}
+
+// FieldError describes one request field that failed validation.
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
@@ -0,0 +1,31 @@
+// Package snaptime parses and formats timestamps the way SNAP BI exchanges
+// them: YYYY-MM-DDTHH:mm:ss followed by an explicit UTC offset such as +07:00.
+package snaptime
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+)
+
+const Layout = "2006-01-02T15:04:05-07:00"
+
+// pattern is stricter than time.RFC3339: the offset must be numeric and the
+// date must use the full extended form. Fractional seconds are tolerated
+// because some acquirers send them.
+var pattern = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}(\.\d{1,9})?[+-]\d{2}:\d{2}$`)
+
+func Parse(value string) (time.Time, error) {
+	if !pattern.MatchString(value) {
+		return time.Time{}, fmt.Errorf("%q is not formatted as YYYY-MM-DDTHH:mm:ss+07:00", value)
+	}
+	t, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%q is not a valid timestamp: %w", value, err)
+	}
+	return t, nil
+}
+
+func Format(t time.Time) string {
+	return t.Format(Layout)
+}